@@ -1,5 +1,3 @@
 module github.com/jbeda/aoc-2024/02-1
 
 go 1.23.3
-
-require golang.org/x/exp v0.0.0-20241108190413-2d47ceb2692f
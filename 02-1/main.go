@@ -2,65 +2,42 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"strconv"
-	"strings"
-
-	"golang.org/x/exp/constraints"
 )
 
-func Abs[T constraints.Integer](x T) T {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
-
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	f, err := os.Open("input.txt")
+	input := flag.String("input", "input.txt", "reports to load")
+	removals := flag.Int("removals", 0, "how many levels may be removed from a report")
+	minStep := flag.Int("min-step", DefaultLimits.MinStep, "smallest allowed change between levels")
+	maxStep := flag.Int("max-step", DefaultLimits.MaxStep, "largest allowed change between levels")
+	explain := flag.Bool("explain", false, "print the levels removed from each report that needed it")
+	flag.Parse()
+
+	f, err := os.Open(*input)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer f.Close()
 
-	scan := bufio.NewScanner(f)
-	var tot int
-
-report:
-	for scan.Scan() {
-		line := scan.Text()
-
-		var report []int
-		ss := strings.Fields(line)
-		for _, s := range ss {
-			n, err := strconv.Atoi(s)
-			if err != nil {
-				log.Fatal(err)
-			}
-			report = append(report, n)
-		}
+	reports, err := ReadReports(bufio.NewScanner(f))
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		last := report[0]
-		increasing := false
-		if report[1] > last {
-			increasing = true
+	l := Limits{*minStep, *maxStep}
+	var tot int
+	for i, report := range reports {
+		removed, ok := l.SafeWithRemovals(report, *removals)
+		if !ok {
+			continue
 		}
-
-		for i := 1; i < len(report); i++ {
-			curr := report[i]
-			if (increasing && curr < last) || (!increasing && curr > last) || curr == last {
-				continue report
-			}
-
-			diff := Abs(curr - last)
-			if diff > 3 {
-				continue report
-			}
-			last = curr
+		if *explain && len(removed) > 0 {
+			fmt.Printf("Report %d %v: remove index %v\n", i+1, report, removed)
 		}
 		tot++
 	}
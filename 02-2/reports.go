@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A report is safe if its levels all go the same way, each step by at least
+// MinStep and at most MaxStep.
+type Limits struct {
+	MinStep, MaxStep int
+}
+
+var DefaultLimits = Limits{1, 3}
+
+func ParseReport(line string) ([]int, error) {
+	var report []int
+	for _, s := range strings.Fields(line) {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, err
+		}
+		report = append(report, n)
+	}
+	return report, nil
+}
+
+func ReadReports(scan *bufio.Scanner) ([][]int, error) {
+	var reports [][]int
+	for scan.Scan() {
+		report, err := ParseReport(scan.Text())
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, scan.Err()
+}
+
+// Whether going from a to b is a good step in direction dir, 1 for
+// increasing and -1 for decreasing.
+func (l Limits) step(a, b, dir int) bool {
+	d := (b - a) * dir
+	return d >= l.MinStep && d <= l.MaxStep
+}
+
+func (l Limits) Safe(report []int) bool {
+	_, ok := l.SafeWithRemovals(report, 0)
+	return ok
+}
+
+// Whether the report can be made safe by removing at most k levels. If it
+// can, also returns the indexes of the fewest levels to remove.
+//
+// For each direction, fewest[i] is the fewest removals from report[:i+1]
+// that leave a safe run ending with report[i] kept. Either everything before
+// i goes, or the previous kept level is some j with a good step from j to i
+// and everything between them goes.
+func (l Limits) SafeWithRemovals(report []int, k int) ([]int, bool) {
+	n := len(report)
+	if n <= 1 {
+		return []int{}, true
+	}
+
+	var best []int
+	for _, dir := range []int{1, -1} {
+		fewest := make([]int, n)
+		prev := make([]int, n)
+		for i := range report {
+			fewest[i], prev[i] = i, -1
+			for j := 0; j < i; j++ {
+				if c := fewest[j] + i - j - 1; c < fewest[i] && l.step(report[j], report[i], dir) {
+					fewest[i], prev[i] = c, j
+				}
+			}
+		}
+
+		last := 0
+		for i := range report {
+			if fewest[i]+n-1-i < fewest[last]+n-1-last {
+				last = i
+			}
+		}
+
+		kept := make([]bool, n)
+		for i := last; i >= 0; i = prev[i] {
+			kept[i] = true
+		}
+		removed := []int{}
+		for i, keep := range kept {
+			if !keep {
+				removed = append(removed, i)
+			}
+		}
+		if best == nil || len(removed) < len(best) {
+			best = removed
+		}
+	}
+
+	if len(best) > k {
+		return nil, false
+	}
+	return best, true
+}
+
+// The report with the levels at the given indexes taken out.
+func Remove(report []int, removed []int) []int {
+	var ret []int
+	for i, v := range report {
+		if !slices.Contains(removed, i) {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,111 @@
+package main
+
+import "testing"
+
+// Check the report straight from the rules.
+func (l Limits) bruteSafe(report []int) bool {
+	for _, dir := range []int{1, -1} {
+		ok := true
+		for i := 1; i < len(report); i++ {
+			ok = ok && l.step(report[i-1], report[i], dir)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Try removing every set of up to k levels, fewest first. Returns the fewest
+// removals that work, or -1 if none do.
+func (l Limits) bruteSafeWithRemovals(report []int, k int) int {
+	var try func(start, left int, removed []int) bool
+	try = func(start, left int, removed []int) bool {
+		if left == 0 {
+			return l.bruteSafe(Remove(report, removed))
+		}
+		for i := start; i < len(report); i++ {
+			if try(i+1, left-1, append(removed, i)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for r := 0; r <= k && r <= len(report); r++ {
+		if try(0, r, nil) {
+			return r
+		}
+	}
+	return -1
+}
+
+func checkReport(t *testing.T, l Limits, report []int, k int) {
+	t.Helper()
+	removed, ok := l.SafeWithRemovals(report, k)
+	want := l.bruteSafeWithRemovals(report, k)
+	switch {
+	case ok != (want >= 0):
+		t.Errorf("%v limits %v k %d: got %v, want %v", report, l, k, ok, want >= 0)
+	case ok && len(removed) != want:
+		t.Errorf("%v limits %v k %d: removed %v, want %d removals", report, l, k, removed, want)
+	case ok && !l.bruteSafe(Remove(report, removed)):
+		t.Errorf("%v limits %v: removing %v doesn't make it safe", report, l, removed)
+	}
+}
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		report []int
+		safe   bool
+		dampen bool
+	}{
+		{[]int{7, 6, 4, 2, 1}, true, true},
+		{[]int{1, 2, 7, 8, 9}, false, false},
+		{[]int{9, 7, 6, 2, 1}, false, false},
+		{[]int{1, 3, 2, 4, 5}, false, true},
+		{[]int{8, 6, 4, 4, 1}, false, true},
+		{[]int{1, 3, 6, 7, 9}, true, true},
+
+		// Removals that touch two steps, which counting bad steps got wrong.
+		{[]int{5, 1, 2, 3, 4}, false, true},
+		{[]int{1, 2, 9, 3, 4}, false, true},
+		{[]int{1, 5, 9, 13}, false, false},
+	}
+	for _, tt := range tests {
+		if _, ok := DefaultLimits.SafeWithRemovals(tt.report, 0); ok != tt.safe {
+			t.Errorf("%v: safe is %v, want %v", tt.report, ok, tt.safe)
+		}
+		if _, ok := DefaultLimits.SafeWithRemovals(tt.report, 1); ok != tt.dampen {
+			t.Errorf("%v: safe with one removal is %v, want %v", tt.report, ok, tt.dampen)
+		}
+		checkReport(t, DefaultLimits, tt.report, 1)
+	}
+}
+
+func TestRemovedIndex(t *testing.T) {
+	removed, ok := DefaultLimits.SafeWithRemovals([]int{1, 2, 9, 3, 4}, 1)
+	if !ok || len(removed) != 1 || removed[0] != 2 {
+		t.Errorf("got %v %v, want [2] true", removed, ok)
+	}
+}
+
+// Levels come from the bytes, kept short so the brute force stays quick.
+func FuzzSafeWithRemovals(f *testing.F) {
+	f.Add([]byte{7, 6, 4, 2, 1}, uint8(1), uint8(1), uint8(2))
+	f.Add([]byte{1, 3, 2, 4, 5}, uint8(1), uint8(1), uint8(2))
+	f.Add([]byte{5, 1, 2, 3, 4}, uint8(1), uint8(1), uint8(2))
+	f.Add([]byte{1, 1, 1, 1}, uint8(2), uint8(0), uint8(0))
+
+	f.Fuzz(func(t *testing.T, levels []byte, k, minStep, span uint8) {
+		if len(levels) > 10 {
+			levels = levels[:10]
+		}
+		report := make([]int, len(levels))
+		for i, b := range levels {
+			report[i] = int(b % 16)
+		}
+		l := Limits{int(minStep % 4), int(minStep%4) + int(span%5)}
+		checkReport(t, l, report, int(k%4))
+	})
+}
@@ -1,42 +1,41 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
-	"strconv"
 )
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	dat, err := os.ReadFile("input.txt")
+	input := flag.String("input", "input.txt", "corrupted memory to run")
+	instructions := flag.String("instructions", "mul", "comma separated instructions to recognize")
+	trace := flag.Bool("trace", false, "print every instruction found with its byte offset")
+	flag.Parse()
+
+	defs, err := LookupInstructions(*instructions)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	re := regexp.MustCompile(`mul\((\d*),(\d*)\)`)
-
-	var tot int
-
-	for _, v := range re.FindAllSubmatch(dat, -1) {
-		fmt.Printf("%q\n", v)
+	f, err := os.Open(*input)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
 
-		as := v[1]
-		a, err := strconv.Atoi(string(as))
-		if err != nil {
-			log.Fatal(err)
-		}
+	m := NewMachine()
+	if err := m.Run(NewTokenizer(f, defs)); err != nil {
+		log.Fatal(err)
+	}
 
-		bs := v[2]
-		b, err := strconv.Atoi(string(bs))
-		if err != nil {
-			log.Fatal(err)
+	if *trace {
+		for _, e := range m.Trace {
+			fmt.Println(e)
 		}
-
-		tot += a * b
 	}
 
-	fmt.Println(tot)
+	fmt.Println(m.Total)
 }
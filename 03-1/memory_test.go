@@ -0,0 +1,98 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// The regex version to check against. Only knows mul, do and don't.
+func regexTotal(dat []byte, defs []*InstructionDef) int {
+	var alts []string
+	for _, d := range defs {
+		switch d {
+		case Mul:
+			alts = append(alts, `mul\((\d{1,3}),(\d{1,3})\)`)
+		case Do:
+			alts = append(alts, `do\(\)`)
+		case Dont:
+			alts = append(alts, `don't\(\)`)
+		}
+	}
+	re := regexp.MustCompile(strings.Join(alts, "|"))
+
+	var tot int
+	enabled := true
+	for _, v := range re.FindAllSubmatch(dat, -1) {
+		switch string(v[0]) {
+		case "do()":
+			enabled = true
+		case "don't()":
+			enabled = false
+		default:
+			a, _ := strconv.Atoi(string(v[1]))
+			b, _ := strconv.Atoi(string(v[2]))
+			if enabled {
+				tot += a * b
+			}
+		}
+	}
+	return tot
+}
+
+var instructionSets = [][]*InstructionDef{
+	{Mul},
+	{Mul, Do, Dont},
+}
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		input string
+		defs  []*InstructionDef
+		want  int
+	}{
+		{"xmul(2,4)%&mul[3,7]!@^do_not_mul(5,5)+mul(32,64]then(mul(11,8)mul(8,5))", instructionSets[0], 161},
+		{"xmul(2,4)&mul[3,7]!^don't()_mul(5,5)+mul(32,64](mul(11,8)undo()?mul(8,5))", instructionSets[1], 48},
+	}
+	for _, tt := range tests {
+		m := NewMachine()
+		if err := m.Run(NewTokenizer(strings.NewReader(tt.input), tt.defs)); err != nil {
+			t.Fatal(err)
+		}
+		if m.Total != tt.want {
+			t.Errorf("%q: got %d, want %d", tt.input, m.Total, tt.want)
+		}
+	}
+}
+
+// Run the tokenizer one byte at a time, so instructions split across reads,
+// and compare it with the regex.
+func FuzzTokenizer(f *testing.F) {
+	f.Add("mul(,)")
+	f.Add("mul(1234,5)")
+	f.Add("don't()mul(2,3)")
+	f.Add("mul(4*mul(12,345)do()mu l(1,2)")
+
+	f.Fuzz(func(t *testing.T, dat string) {
+		for _, defs := range instructionSets {
+			m := NewMachine()
+			r := iotest.OneByteReader(strings.NewReader(dat))
+			if err := m.Run(NewTokenizer(r, defs)); err != nil {
+				t.Fatalf("%q: %v", dat, err)
+			}
+
+			if want := regexTotal([]byte(dat), defs); m.Total != want {
+				t.Errorf("%q with %d instructions: got %d, want %d", dat, len(defs), m.Total, want)
+			}
+
+			// Every traced instruction has to be in the input where it says.
+			for _, e := range m.Trace {
+				if !strings.HasPrefix(dat[e.Offset:], e.Instruction.String()) {
+					t.Errorf("trace entry %v not at its offset in %q", e, dat)
+				}
+			}
+		}
+	})
+}
@@ -1,49 +1,41 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
-	"strconv"
 )
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	dat, err := os.ReadFile("input.txt")
+	input := flag.String("input", "input.txt", "corrupted memory to run")
+	instructions := flag.String("instructions", "mul,do,don't", "comma separated instructions to recognize")
+	trace := flag.Bool("trace", false, "print every instruction found with its byte offset")
+	flag.Parse()
+
+	defs, err := LookupInstructions(*instructions)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(*input)
 	if err != nil {
 		log.Fatal(err)
 	}
+	defer f.Close()
+
+	m := NewMachine()
+	if err := m.Run(NewTokenizer(f, defs)); err != nil {
+		log.Fatal(err)
+	}
 
-	re := regexp.MustCompile(`mul\((\d*),(\d*)\)|do\(\)|don't\(\)`)
-
-	var tot int
-	enabled := true
-
-	for _, v := range re.FindAllSubmatch(dat, -1) {
-		fmt.Printf("enabled: %t - %q\n", enabled, v)
-
-		switch string(v[0]) {
-		case "do()":
-			enabled = true
-		case "don't()":
-			enabled = false
-		default:
-			a, err := strconv.Atoi(string(v[1]))
-			if err != nil {
-				log.Fatal(err)
-			}
-
-			b, err := strconv.Atoi(string(v[2]))
-			if err != nil {
-				log.Fatal(err)
-			}
-			if enabled {
-				tot += a * b
-			}
+	if *trace {
+		for _, e := range m.Trace {
+			fmt.Println(e)
 		}
 	}
 
-	fmt.Println(tot)
+	fmt.Println(m.Total)
 }
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A streaming interpreter for the corrupted memory. Instructions look like
+// name(arg,arg) where each argument is 1 to 3 digits. Anything that doesn't
+// match exactly is skipped a byte at a time, so "mul(4*" or "mul(1234,5)"
+// are just noise.
+
+const MaxDigits = 3
+
+type InstructionDef struct {
+	Name    string
+	NumArgs int
+
+	// Run the instruction. Returns what it added to the total.
+	Exec func(m *Machine, args []int) int
+}
+
+// The length of the longest possible match, used to size the lookahead.
+func (d *InstructionDef) maxLen() int {
+	return len(d.Name) + 2 + d.NumArgs*MaxDigits + max(d.NumArgs-1, 0)
+}
+
+// If buf starts with the instruction, return its arguments and length.
+func (d *InstructionDef) match(buf []byte) ([]int, int, bool) {
+	if !strings.HasPrefix(string(buf), d.Name+"(") {
+		return nil, 0, false
+	}
+	i := len(d.Name) + 1
+
+	args := make([]int, 0, d.NumArgs)
+	for a := 0; a < d.NumArgs; a++ {
+		if a > 0 {
+			if i >= len(buf) || buf[i] != ',' {
+				return nil, 0, false
+			}
+			i++
+		}
+
+		start := i
+		for i < len(buf) && i-start < MaxDigits && buf[i] >= '0' && buf[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return nil, 0, false
+		}
+		n, _ := strconv.Atoi(string(buf[start:i]))
+		args = append(args, n)
+	}
+
+	if i >= len(buf) || buf[i] != ')' {
+		return nil, 0, false
+	}
+	return args, i + 1, true
+}
+
+var Mul = &InstructionDef{"mul", 2, func(m *Machine, args []int) int {
+	if !m.Enabled {
+		return 0
+	}
+	return args[0] * args[1]
+}}
+
+var Do = &InstructionDef{"do", 0, func(m *Machine, args []int) int {
+	m.Enabled = true
+	return 0
+}}
+
+var Dont = &InstructionDef{"don't", 0, func(m *Machine, args []int) int {
+	m.Enabled = false
+	return 0
+}}
+
+// Every instruction that can be picked by name.
+var Registry = []*InstructionDef{Mul, Do, Dont}
+
+func RegisterInstruction(d *InstructionDef) {
+	Registry = append(Registry, d)
+}
+
+// Parse a comma separated list of instruction names like "mul,do,don't".
+func LookupInstructions(names string) ([]*InstructionDef, error) {
+	var defs []*InstructionDef
+next:
+	for _, name := range strings.Split(names, ",") {
+		for _, d := range Registry {
+			if d.Name == name {
+				defs = append(defs, d)
+				continue next
+			}
+		}
+		return nil, fmt.Errorf("unknown instruction %q", name)
+	}
+	return defs, nil
+}
+
+// -------------------------------------
+// One instruction found in the input.
+type Instruction struct {
+	Def    *InstructionDef
+	Args   []int
+	Offset int64
+
+	// Exactly as it was in the input.
+	Text string
+}
+
+func (in Instruction) String() string {
+	return in.Text
+}
+
+type Tokenizer struct {
+	r      *bufio.Reader
+	defs   []*InstructionDef
+	offset int64
+
+	// The bytes that can start an instruction, and the most to look ahead.
+	first     [256]bool
+	lookahead int
+}
+
+func NewTokenizer(r io.Reader, defs []*InstructionDef) *Tokenizer {
+	t := &Tokenizer{defs: defs}
+	for _, d := range defs {
+		t.first[d.Name[0]] = true
+		t.lookahead = max(t.lookahead, d.maxLen())
+	}
+	t.r = bufio.NewReaderSize(r, max(4096, t.lookahead))
+	return t
+}
+
+// The next instruction, or io.EOF once the input runs out.
+func (t *Tokenizer) Next() (Instruction, error) {
+	for {
+		buf, err := t.r.Peek(t.lookahead)
+		if len(buf) == 0 {
+			if err == nil || errors.Is(err, bufio.ErrBufferFull) {
+				err = io.EOF
+			}
+			return Instruction{}, err
+		}
+		if err != nil && err != io.EOF {
+			return Instruction{}, err
+		}
+
+		if t.first[buf[0]] {
+			for _, d := range t.defs {
+				if args, n, ok := d.match(buf); ok {
+					in := Instruction{d, args, t.offset, string(buf[:n])}
+					t.r.Discard(n)
+					t.offset += int64(n)
+					return in, nil
+				}
+			}
+		}
+
+		t.r.Discard(1)
+		t.offset++
+	}
+}
+
+// -------------------------------------
+// What an instruction did when it ran.
+type TraceEntry struct {
+	Instruction
+	Enabled bool
+	Added   int
+}
+
+func (e TraceEntry) String() string {
+	return fmt.Sprintf("%8d: %-14s enabled: %-5t +%d", e.Offset, e.Instruction, e.Enabled, e.Added)
+}
+
+type Machine struct {
+	Enabled bool
+	Total   int
+	Trace   []TraceEntry
+}
+
+func NewMachine() *Machine {
+	return &Machine{Enabled: true}
+}
+
+// Run every instruction from the tokenizer.
+func (m *Machine) Run(t *Tokenizer) error {
+	for {
+		in, err := t.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		enabled := m.Enabled
+		added := in.Def.Exec(m, in.Args)
+		m.Total += added
+		m.Trace = append(m.Trace, TraceEntry{in, enabled, added})
+	}
+}
@@ -1,66 +1,52 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	f, err := os.Open("input.txt")
+	input := flag.String("input", "input.txt", "the word search")
+	words := flag.String("words", "XMAS", "words to find, separated by commas")
+	list := flag.Bool("list", false, "print every match")
+	highlight := flag.Bool("highlight", false, "draw the grid with only the matches showing")
+	color := flag.Bool("color", false, "draw the whole grid with the matches in color")
+	flag.Parse()
+
+	f, err := os.Open(*input)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer f.Close()
 
-	var grid [][]rune
-
-	scan := bufio.NewScanner(f)
-	for scan.Scan() {
-		line := scan.Text()
-		grid = append(grid, []rune(line))
+	grid, err := ReadGrid(f)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	var target = []rune("XMAS")
-	var offsets = []struct{ x, y int }{
-		{1, 0}, {-1, 0}, // Horizontal
-		{0, 1}, {0, -1}, // Vertical
-		{1, 1}, {-1, -1}, {1, -1}, {-1, 1}, // Diagonal
+	var patterns []Pattern
+	for _, s := range strings.Split(*words, ",") {
+		ps, err := WordPatterns(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		patterns = append(patterns, ps...)
 	}
 
-	var tot int
-	for y, row := range grid {
-		for x, ch := range row {
-			if ch != target[0] {
-				continue
-			}
-
-			for _, offset := range offsets {
-				var found = true
-				for i, ch := range target {
-					if x+i*offset.x < 0 || x+i*offset.x >= len(row) {
-						found = false
-						break
-					}
-					if y+i*offset.y < 0 || y+i*offset.y >= len(grid) {
-						found = false
-						break
-					}
-					if grid[y+i*offset.y][x+i*offset.x] != ch {
-						found = false
-						break
-					}
-				}
-				if found {
-					log.Printf("Found %s at (%d, %d)\n", string(target), x, y)
-					tot++
-				}
-			}
+	matches := Search(grid, patterns)
+	if *list {
+		for _, m := range matches {
+			fmt.Println(m)
 		}
 	}
+	if *highlight || *color {
+		fmt.Print(Highlight(grid, matches, *color))
+	}
 
-	fmt.Println(tot)
+	fmt.Println(len(matches))
 }
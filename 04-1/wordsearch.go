@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A word search over any patterns. A pattern is a small grid of runes where
+// '.' matches anything. Every rotation and reflection of it is searched for,
+// with duplicates dropped so a symmetric pattern isn't counted twice at the
+// same spot.
+
+const Wildcard = '.'
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+type Grid [][]rune
+
+func ReadGrid(r io.Reader) (Grid, error) {
+	var grid Grid
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		line := scan.Text()
+		grid = append(grid, []rune(line))
+	}
+	return grid, scan.Err()
+}
+
+func (g Grid) At(p Point) (rune, bool) {
+	if p.Y < 0 || p.Y >= len(g) || p.X < 0 || p.X >= len(g[p.Y]) {
+		return 0, false
+	}
+	return g[p.Y][p.X], true
+}
+
+func (g Grid) String() string {
+	var sb strings.Builder
+	for _, row := range g {
+		sb.WriteString(string(row))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// -------------------------------------
+type Pattern struct {
+	Name string
+	Grid Grid
+}
+
+// A pattern needs at least one letter. One that is empty or all wildcards
+// would match at every cell.
+func (p Pattern) validate() error {
+	for _, row := range p.Grid {
+		for _, r := range row {
+			if r != Wildcard {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("pattern %q has no letters to match", p.Name)
+}
+
+// Parse a pattern written with its rows separated by '/', like "M.S/.A./M.S".
+// Short rows are padded with wildcards so the pattern is a rectangle, and
+// rows and columns of wildcards around the edge are trimmed off. Otherwise
+// ".X" would turn into four different grids that all match the same X.
+func ParsePattern(s string) (Pattern, error) {
+	var g Grid
+	w := 0
+	for _, row := range strings.Split(s, "/") {
+		g = append(g, []rune(row))
+		w = max(w, len(g[len(g)-1]))
+	}
+	for y, row := range g {
+		for len(row) < w {
+			row = append(row, Wildcard)
+		}
+		g[y] = row
+	}
+
+	p := Pattern{s, g}
+	if err := p.validate(); err != nil {
+		return p, err
+	}
+	p.Grid = g.trim()
+	return p, nil
+}
+
+func blank(runes []rune) bool {
+	for _, r := range runes {
+		if r != Wildcard {
+			return false
+		}
+	}
+	return true
+}
+
+// Cut off rows and columns that are all wildcards from the edges of a
+// rectangular grid with at least one letter.
+func (g Grid) trim() Grid {
+	for blank(g[0]) {
+		g = g[1:]
+	}
+	for blank(g[len(g)-1]) {
+		g = g[:len(g)-1]
+	}
+
+	column := func(x int) []rune {
+		col := make([]rune, len(g))
+		for y, row := range g {
+			col[y] = row[x]
+		}
+		return col
+	}
+	left, right := 0, len(g[0])
+	for blank(column(left)) {
+		left++
+	}
+	for blank(column(right - 1)) {
+		right--
+	}
+
+	out := make(Grid, len(g))
+	for y, row := range g {
+		out[y] = row[left:right]
+	}
+	return out
+}
+
+// The patterns for a word: written across and written along the diagonal.
+// Between them their rotations cover all eight directions.
+func WordPatterns(word string) ([]Pattern, error) {
+	runes := []rune(word)
+	if err := (Pattern{word, Grid{runes}}).validate(); err != nil {
+		return nil, err
+	}
+
+	diag := make(Grid, len(runes))
+	for i := range diag {
+		diag[i] = []rune(strings.Repeat(string(Wildcard), len(runes)))
+		diag[i][i] = runes[i]
+	}
+	return []Pattern{{word, Grid{runes}}, {word, diag}}, nil
+}
+
+// How a pattern was turned before matching: reflected left to right first,
+// then rotated clockwise by quarter turns.
+type Orientation struct {
+	Reflected bool
+	Rotation  int
+}
+
+func (o Orientation) String() string {
+	s := fmt.Sprintf("rotated %d", o.Rotation*90)
+	if o.Reflected {
+		s = "reflected, " + s
+	}
+	return s
+}
+
+// A pattern in one orientation.
+type Oriented struct {
+	Pattern     *Pattern
+	Orientation Orientation
+	Grid        Grid
+}
+
+// Rotate and reflect expect a rectangle, which ParsePattern makes sure of.
+func (g Grid) rotate() Grid {
+	h := len(g)
+	w := 0
+	for _, row := range g {
+		w = max(w, len(row))
+	}
+	out := make(Grid, w)
+	for x := 0; x < w; x++ {
+		out[x] = make([]rune, h)
+		for y := 0; y < h; y++ {
+			r := Wildcard
+			if x < len(g[h-1-y]) {
+				r = g[h-1-y][x]
+			}
+			out[x][y] = r
+		}
+	}
+	return out
+}
+
+func (g Grid) reflect() Grid {
+	out := make(Grid, len(g))
+	for y, row := range g {
+		out[y] = make([]rune, len(row))
+		for x, r := range row {
+			out[y][len(row)-1-x] = r
+		}
+	}
+	return out
+}
+
+// Every distinct rotation and reflection of the patterns.
+func Orientations(patterns []Pattern) []*Oriented {
+	var ret []*Oriented
+	seen := make(map[string]bool)
+	for i := range patterns {
+		p := &patterns[i]
+		for _, reflected := range []bool{false, true} {
+			g := p.Grid
+			if reflected {
+				g = g.reflect()
+			}
+			for rot := 0; rot < 4; rot++ {
+				key := g.String()
+				if !seen[key] {
+					seen[key] = true
+					ret = append(ret, &Oriented{p, Orientation{reflected, rot}, g})
+				}
+				g = g.rotate()
+			}
+		}
+	}
+	return ret
+}
+
+// -------------------------------------
+type Match struct {
+	Oriented *Oriented
+
+	// The top left of the pattern in the grid and the cells it matched,
+	// leaving out wildcards.
+	Pos   Point
+	Cells []Point
+}
+
+func (m Match) String() string {
+	return fmt.Sprintf("%s at %v, %v", m.Oriented.Pattern.Name, m.Pos, m.Oriented.Orientation)
+}
+
+func (o *Oriented) matchAt(g Grid, pos Point) ([]Point, bool) {
+	var cells []Point
+	for j, row := range o.Grid {
+		for i, want := range row {
+			if want == Wildcard {
+				continue
+			}
+			p := Point{pos.X + i, pos.Y + j}
+			if got, ok := g.At(p); !ok || got != want {
+				return nil, false
+			}
+			cells = append(cells, p)
+		}
+	}
+	return cells, true
+}
+
+// Every place any orientation of the patterns matches.
+func Search(g Grid, patterns []Pattern) []Match {
+	orientations := Orientations(patterns)
+	var ret []Match
+	for y, row := range g {
+		for x := range row {
+			for _, o := range orientations {
+				if cells, ok := o.matchAt(g, Point{x, y}); ok {
+					ret = append(ret, Match{o, Point{x, y}, cells})
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// -------------------------------------
+// Draw the grid with only the matched letters showing, like the puzzle's
+// examples. With color the whole grid is shown and matches are highlighted
+// with ANSI escapes instead.
+func Highlight(g Grid, matches []Match, color bool) string {
+	hit := make(map[Point]bool)
+	for _, m := range matches {
+		for _, p := range m.Cells {
+			hit[p] = true
+		}
+	}
+
+	var sb strings.Builder
+	for y, row := range g {
+		for x, r := range row {
+			switch {
+			case hit[Point{x, y}] && color:
+				sb.WriteString("\x1b[1;33m" + string(r) + "\x1b[0m")
+			case hit[Point{x, y}] || color:
+				sb.WriteRune(r)
+			default:
+				sb.WriteRune(Wildcard)
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
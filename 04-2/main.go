@@ -1,79 +1,52 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 )
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	f, err := os.Open("input.txt")
+	input := flag.String("input", "input.txt", "the word search")
+	pattern := flag.String("pattern", "M.S/.A./M.S", "patterns to find, separated by commas, rows separated by '/' and '.' for any letter")
+	list := flag.Bool("list", false, "print every match")
+	highlight := flag.Bool("highlight", false, "draw the grid with only the matches showing")
+	color := flag.Bool("color", false, "draw the whole grid with the matches in color")
+	flag.Parse()
+
+	f, err := os.Open(*input)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer f.Close()
 
-	var grid [][]rune
-
-	scan := bufio.NewScanner(f)
-	for scan.Scan() {
-		line := scan.Text()
-		grid = append(grid, []rune(line))
+	grid, err := ReadGrid(f)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	var targets = [][][]rune{
-		{
-			[]rune("M.M"),
-			[]rune(".A."),
-			[]rune("S.S"),
-		},
-		{
-			[]rune("S.S"),
-			[]rune(".A."),
-			[]rune("M.M"),
-		},
-		{
-			[]rune("M.S"),
-			[]rune(".A."),
-			[]rune("M.S"),
-		},
-		{
-			[]rune("S.M"),
-			[]rune(".A."),
-			[]rune("S.M"),
-		},
+	var patterns []Pattern
+	for _, s := range strings.Split(*pattern, ",") {
+		p, err := ParsePattern(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		patterns = append(patterns, p)
 	}
 
-	var tot int
-	for y, row := range grid {
-		for x := range row {
-			for _, target := range targets {
-				var found = true
-			target:
-				for j, trow := range target {
-					for i, tch := range trow {
-						if x+i >= len(row) || y+j >= len(grid) {
-							found = false
-							break target
-						}
-						if tch == '.' {
-							continue
-						}
-						if grid[y+j][x+i] != tch {
-							found = false
-							break target
-						}
-					}
-				}
-				if found {
-					tot++
-				}
-			}
+	matches := Search(grid, patterns)
+	if *list {
+		for _, m := range matches {
+			fmt.Println(m)
 		}
 	}
+	if *highlight || *color {
+		fmt.Print(Highlight(grid, matches, *color))
+	}
 
-	fmt.Println(tot)
+	fmt.Println(len(matches))
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+const example = `MMMSXXMASM
+MSAMXMSMSA
+AMXSXMAAMM
+MSAMASMSMX
+XMASAMXAMM
+XXAMMXXAMA
+SMSMSASXSS
+SAXAMASAAA
+MAMMMXMMMM
+MXMXAXMASX
+`
+
+func TestExample(t *testing.T) {
+	g, err := ReadGrid(strings.NewReader(example))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := WordPatterns("XMAS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(Search(g, words)); got != 18 {
+		t.Errorf("XMAS: got %d matches, want 18", got)
+	}
+
+	cross, err := ParsePattern("M.S/.A./M.S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(Search(g, []Pattern{cross})); got != 9 {
+		t.Errorf("X-MAS: got %d matches, want 9", got)
+	}
+}
+
+func TestEmptyPatterns(t *testing.T) {
+	for _, s := range []string{"", "...", "./."} {
+		if _, err := ParsePattern(s); err == nil {
+			t.Errorf("ParsePattern(%q) gave no error", s)
+		}
+	}
+	if _, err := WordPatterns(""); err == nil {
+		t.Error("WordPatterns(\"\") gave no error")
+	}
+}
+
+func search(t *testing.T, grid, pattern string) []Match {
+	t.Helper()
+	g, err := ReadGrid(strings.NewReader(grid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := ParsePattern(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Search(g, []Pattern{p})
+}
+
+// A ragged pattern has to be padded before it is reflected, or the short
+// row ends up mirrored in the wrong place.
+func TestRaggedMirror(t *testing.T) {
+	for _, pattern := range []string{"AB/C", "AB/C."} {
+		if got := len(search(t, "BA\n.C\n", pattern)); got != 1 {
+			t.Errorf("%q: got %d matches in its mirror image, want 1", pattern, got)
+		}
+	}
+}
+
+// Symmetric patterns and ones with a border of wildcards only count once
+// for each place they match.
+func TestSymmetricOnce(t *testing.T) {
+	tests := []struct {
+		grid, pattern string
+		want          int
+	}{
+		{"X\n", ".X", 1},
+		{"X\n", "./X/.", 1},
+		{".X.\nXXX\n.X.\n", ".X./XXX/.X.", 1},
+		{"ABA\n", "ABA", 1},
+		{"A.A\n.A.\nA.A\n", "A.A/.A./A.A", 1},
+	}
+	for _, tt := range tests {
+		if got := len(search(t, tt.grid, tt.pattern)); got != tt.want {
+			t.Errorf("%q: got %d matches, want %d", tt.pattern, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Page ordering rules as a graph. Each update only cares about the rules
+// between its own pages, so the graph is cut down to those pages before it
+// is sorted. The full set of rules has cycles, so nothing global works.
+
+// Page Before has to be printed before page After.
+type Rule struct {
+	Before, After int
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%d|%d", r.Before, r.After)
+}
+
+type Rules struct {
+	// Key is a page. Value is the set of pages that must come after it.
+	after map[int]map[int]bool
+	List  []Rule
+}
+
+func NewRules() *Rules {
+	return &Rules{after: make(map[int]map[int]bool)}
+}
+
+func (rs *Rules) Add(r Rule) {
+	set, found := rs.after[r.Before]
+	if !found {
+		set = make(map[int]bool)
+		rs.after[r.Before] = set
+	}
+	if !set[r.After] {
+		set[r.After] = true
+		rs.List = append(rs.List, r)
+	}
+}
+
+func (rs *Rules) Has(before, after int) bool {
+	return rs.after[before][after]
+}
+
+// Parse a rule in the form <int>|<int>
+func ParseRule(line string) (Rule, error) {
+	ss := strings.Split(line, "|")
+	if len(ss) != 2 {
+		return Rule{}, fmt.Errorf("invalid rule: %s", line)
+	}
+	before, err := strconv.Atoi(ss[0])
+	if err != nil {
+		return Rule{}, err
+	}
+	after, err := strconv.Atoi(ss[1])
+	if err != nil {
+		return Rule{}, err
+	}
+	return Rule{before, after}, nil
+}
+
+// Read rules up to the first blank line.
+func ReadRules(scan *bufio.Scanner) (*Rules, error) {
+	rs := NewRules()
+	for scan.Scan() {
+		line := scan.Text()
+		if len(line) == 0 {
+			break
+		}
+		r, err := ParseRule(line)
+		if err != nil {
+			return nil, err
+		}
+		rs.Add(r)
+	}
+	return rs, scan.Err()
+}
+
+// Read the comma separated updates that follow the rules.
+func ReadUpdates(scan *bufio.Scanner) ([][]int, error) {
+	var updates [][]int
+	for scan.Scan() {
+		var update []int
+		for _, s := range strings.Split(scan.Text(), ",") {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return nil, err
+			}
+			update = append(update, n)
+		}
+		updates = append(updates, update)
+	}
+	return updates, scan.Err()
+}
+
+// -------------------------------------
+// A rule broken by an update: Rule.After is printed at AfterIndex, before
+// Rule.Before at BeforeIndex.
+type Violation struct {
+	Rule        Rule
+	BeforeIndex int
+	AfterIndex  int
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("rule %v broken: %d is at position %d but %d is at position %d",
+		v.Rule, v.Rule.After, v.AfterIndex, v.Rule.Before, v.BeforeIndex)
+}
+
+// Every rule the update breaks, in the order the pages appear.
+func (rs *Rules) Violations(update []int) []Violation {
+	var ret []Violation
+	for i, a := range update {
+		for j := i + 1; j < len(update); j++ {
+			if rs.Has(update[j], a) {
+				ret = append(ret, Violation{Rule{update[j], a}, j, i})
+			}
+		}
+	}
+	return ret
+}
+
+func (rs *Rules) Correct(update []int) bool {
+	return len(rs.Violations(update)) == 0
+}
+
+// The rules restricted to some pages have a cycle. Pages are in rule order
+// and the last one has to come before the first.
+type CycleError struct {
+	Pages []int
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("rules have a cycle between pages %v", e.Pages)
+}
+
+// More than one page could have gone next, so the order isn't the only one.
+type AmbiguousError struct {
+	Index   int
+	Choices []int
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("order is ambiguous at position %d: any of %v could go next", e.Index, e.Choices)
+}
+
+// Topologically sort the pages of an update by the rules between them, with
+// Kahn's algorithm. On a cycle there is no order and a *CycleError is
+// returned. If the rules leave a choice somewhere the order still comes back,
+// taking the lowest page each time, along with an *AmbiguousError for the
+// first choice.
+func (rs *Rules) Sort(update []int) ([]int, error) {
+	pages := make(map[int]bool)
+	for _, p := range update {
+		if pages[p] {
+			return nil, fmt.Errorf("page %d is in the update twice", p)
+		}
+		pages[p] = true
+	}
+
+	inDegree := make(map[int]int)
+	for _, p := range update {
+		for q := range rs.after[p] {
+			if pages[q] {
+				inDegree[q]++
+			}
+		}
+	}
+
+	ready := []int{}
+	for _, p := range update {
+		if inDegree[p] == 0 {
+			ready = append(ready, p)
+		}
+	}
+
+	var ambiguous error
+	order := make([]int, 0, len(update))
+	for len(ready) > 0 {
+		slices.Sort(ready)
+		if len(ready) > 1 && ambiguous == nil {
+			ambiguous = &AmbiguousError{len(order), slices.Clone(ready)}
+		}
+
+		p := ready[0]
+		ready = ready[1:]
+		order = append(order, p)
+
+		for q := range rs.after[p] {
+			if !pages[q] {
+				continue
+			}
+			inDegree[q]--
+			if inDegree[q] == 0 {
+				ready = append(ready, q)
+			}
+		}
+	}
+
+	if len(order) < len(update) {
+		stuck := make(map[int]bool)
+		for _, p := range update {
+			stuck[p] = inDegree[p] > 0
+		}
+		return nil, &CycleError{rs.findCycle(update, stuck)}
+	}
+	return order, ambiguous
+}
+
+// Every page left over when Kahn's algorithm stops still has a rule from
+// another left over page, so walking those rules backward has to loop.
+func (rs *Rules) findCycle(update []int, stuck map[int]bool) []int {
+	var p int
+	for _, p = range update {
+		if stuck[p] {
+			break
+		}
+	}
+
+	seen := make(map[int]int)
+	path := []int{}
+	for {
+		if i, ok := seen[p]; ok {
+			cycle := path[i:]
+			slices.Reverse(cycle)
+			return cycle
+		}
+		seen[p] = len(path)
+		path = append(path, p)
+
+		for _, q := range update {
+			if stuck[q] && rs.Has(q, p) {
+				p = q
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"math/bits"
+	"runtime"
+	"sync"
+)
+
+// Loop detection with jump tables. Instead of stepping a cell at a time the
+// guard jumps straight to the cell in front of the next obstacle. One extra
+// obstacle is handled at query time by checking if it sits on the jump, so
+// nothing is copied or rebuilt per candidate.
+
+var dirOrder = []Dir{DirUp, DirRight, DirDown, DirLeft}
+
+func (d Dir) Index() int {
+	return bits.TrailingZeros(uint(d))
+}
+
+func (d Dir) Right() Dir {
+	return dirOrder[(d.Index()+1)%4]
+}
+
+func (d Dir) Delta() Pos {
+	switch d {
+	case DirUp:
+		return Pos{0, -1}
+	case DirRight:
+		return Pos{1, 0}
+	case DirDown:
+		return Pos{0, 1}
+	case DirLeft:
+		return Pos{-1, 0}
+	}
+	return Pos{}
+}
+
+type JumpTable struct {
+	W, H     int
+	Obstacle []bool
+
+	// For each direction and cell, the last free cell before an obstacle or
+	// the edge, and whether it stopped on an obstacle (false means it walks
+	// off the board).
+	stop    [4][]int32
+	blocked [4][]bool
+}
+
+func NewJumpTable(b *Board) *JumpTable {
+	jt := &JumpTable{
+		H: len(b.grid),
+		W: len(b.grid[0]),
+	}
+	n := jt.W * jt.H
+	jt.Obstacle = make([]bool, n)
+	for y, line := range b.grid {
+		for x, cell := range line {
+			jt.Obstacle[y*jt.W+x] = cell.visited == CellObstacle
+		}
+	}
+
+	for _, d := range dirOrder {
+		di := d.Index()
+		jt.stop[di] = make([]int32, n)
+		jt.blocked[di] = make([]bool, n)
+
+		// Sweep from the far side in direction d so the cell ahead is always
+		// done first.
+		delta := d.Delta()
+		for i := 0; i < n; i++ {
+			x, y := i%jt.W, i/jt.W
+			if delta.X > 0 {
+				x = jt.W - 1 - x
+			}
+			if delta.Y > 0 {
+				y = jt.H - 1 - y
+			}
+			idx := y*jt.W + x
+			if jt.Obstacle[idx] {
+				continue
+			}
+
+			ahead := Pos{x + delta.X, y + delta.Y}
+			switch {
+			case ahead.X < 0 || ahead.Y < 0 || ahead.X >= jt.W || ahead.Y >= jt.H:
+				jt.stop[di][idx] = int32(idx)
+			case jt.Obstacle[ahead.Y*jt.W+ahead.X]:
+				jt.stop[di][idx] = int32(idx)
+				jt.blocked[di][idx] = true
+			default:
+				aheadIdx := ahead.Y*jt.W + ahead.X
+				jt.stop[di][idx] = jt.stop[di][aheadIdx]
+				jt.blocked[di][idx] = jt.blocked[di][aheadIdx]
+			}
+		}
+	}
+	return jt
+}
+
+// Jump from a cell in a direction. extra is the index of one added obstacle
+// or -1. Returns where the guard stops and whether it hit something.
+func (jt *JumpTable) Jump(from int, d Dir, extra int) (int, bool) {
+	di := d.Index()
+	stop, blocked := int(jt.stop[di][from]), jt.blocked[di][from]
+	if extra < 0 {
+		return stop, blocked
+	}
+
+	// Is the extra obstacle between from (exclusive) and stop (inclusive)?
+	step := d.Delta().Y*jt.W + d.Delta().X
+	fx, fy := from%jt.W, from/jt.W
+	ex, ey := extra%jt.W, extra/jt.W
+	onLine := ((step == 1 || step == -1) && fy == ey) || ((step == jt.W || step == -jt.W) && fx == ex)
+	if onLine && (extra-from)*step > 0 && (stop-extra)*step >= 0 {
+		return extra - step, true
+	}
+	return stop, blocked
+}
+
+// Walk from a position until the guard leaves or repeats a state. seen and
+// stamp let callers reuse one visited array across many walks.
+func (jt *JumpTable) Loops(pos int, d Dir, extra int, seen []int32, stamp int32) bool {
+	for {
+		key := pos*4 + d.Index()
+		if seen[key] == stamp {
+			return true
+		}
+		seen[key] = stamp
+
+		stop, blocked := jt.Jump(pos, d, extra)
+		if !blocked {
+			return false
+		}
+		pos = stop
+		d = d.Right()
+	}
+}
+
+type candidate struct {
+	Obstacle int
+	Pos      int
+	Dir      Dir
+}
+
+// Walk the guard's path and, for each cell the first time it is about to
+// enter it, record the state in front of it. Putting an obstacle anywhere
+// else can't change the path.
+func (jt *JumpTable) Candidates(start Pos, d Dir) []candidate {
+	ret := []candidate{}
+	seen := make([]bool, jt.W*jt.H)
+	pos := start
+	seen[pos.Y*jt.W+pos.X] = true
+
+	for {
+		delta := d.Delta()
+		next := Pos{pos.X + delta.X, pos.Y + delta.Y}
+		if next.X < 0 || next.Y < 0 || next.X >= jt.W || next.Y >= jt.H {
+			return ret
+		}
+
+		nextIdx := next.Y*jt.W + next.X
+		if jt.Obstacle[nextIdx] {
+			d = d.Right()
+			continue
+		}
+		if !seen[nextIdx] {
+			seen[nextIdx] = true
+			ret = append(ret, candidate{nextIdx, pos.Y*jt.W + pos.X, d})
+		}
+		pos = next
+	}
+}
+
+// Count the positions where one new obstacle traps the guard in a loop. The
+// candidates are split across goroutines, each with its own visited array.
+func (jt *JumpTable) CountLoops(start Pos, d Dir) int {
+	candidates := jt.Candidates(start, d)
+	numWorkers := runtime.NumCPU()
+
+	var wg sync.WaitGroup
+	counts := make([]int, numWorkers)
+	for w := 0; w < numWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seen := make([]int32, jt.W*jt.H*4)
+			for i := w; i < len(candidates); i += numWorkers {
+				c := candidates[i]
+				if jt.Loops(c.Pos, c.Dir, c.Obstacle, seen, int32(i+1)) {
+					counts[w]++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	return total
+}
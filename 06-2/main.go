@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -188,11 +189,9 @@ func (b *Board) Print() {
 	}
 }
 
-func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-
-	b := LoadBoard("input.txt")
-
+// Count loops by walking the path and, at each new cell, cloning the board
+// with an obstacle there and walking it to a loop or the exit.
+func CountLoopsClone(b *Board) int {
 	var loops int
 
 	// Move the player
@@ -229,6 +228,28 @@ func main() {
 		}
 	}
 
+	return loops
+}
+
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	input := flag.String("input", "input.txt", "map to load")
+	impl := flag.String("impl", "jump", "implementation to use: jump or clone")
+	flag.Parse()
+
+	b := LoadBoard(*input)
+
+	var loops int
+	switch *impl {
+	case "jump":
+		loops = NewJumpTable(b).CountLoops(b.playerPos, b.playerDir)
+	case "clone":
+		loops = CountLoopsClone(b)
+	default:
+		log.Fatalf("Unknown impl %q", *impl)
+	}
+
 	fmt.Println(loops)
 
 }
@@ -2,104 +2,46 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"strconv"
-	"strings"
 )
 
-// Do a textual split of two integers.  Remove b from teh end of a.
-func split(a, b int64) (int64, bool) {
-	sa := strconv.FormatInt(a, 10)
-	sb := strconv.FormatInt(b, 10)
-
-	if strings.HasSuffix(sa, sb) {
-		trimmed := strings.TrimSuffix(sa, sb)
-		if trimmed == "" || trimmed[0] == '-' {
-			return 0, false
-		}
-		r, err := strconv.ParseInt(trimmed, 10, 64)
-		if err != nil {
-			log.Fatal(err)
-		}
-		return r, true
-	}
-	return 0, false
-}
-
-// Search possible combination of operators to produce total based on the
-// inputs. Return true if a combination is found, false otherwise.
-func Search(inputs []int64, total int64) bool {
-	// Base case: last input stands alone
-	if len(inputs) == 1 {
-		return inputs[0] == total
-	}
-
-	// Pop off the last elemeent
-	a := inputs[:len(inputs)-1]
-	b := inputs[len(inputs)-1]
+func main() {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	// try addition
-	if b < total && Search(a, total-b) {
-		return true
-	}
+	input := flag.String("input", "input.txt", "equations to load")
+	opsFlag := flag.String("ops", "+,*,||", "comma separated operators to try, from +, *, ||, - and ^")
+	show := flag.Bool("show", false, "print the operators that solve each equation")
+	workers := flag.Int("workers", 0, "goroutines to solve with, 0 for one per CPU")
+	flag.Parse()
 
-	// try multiplication
-	q := total / b
-	r := total % b
-	if r == 0 && Search(a, q) {
-		return true
+	ops, err := ParseOperators(*opsFlag)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// try concatenation
-	s, ok := split(total, b)
-	if ok && Search(a, s) {
-		return true
+	f, err := os.Open(*input)
+	if err != nil {
+		log.Fatal(err)
 	}
+	defer f.Close()
 
-	return false
-}
-
-func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-
-	f, err := os.Open("input.txt")
+	eqs, err := ReadEquations(bufio.NewScanner(f))
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer f.Close()
 
 	var tot int64
-
-	scan := bufio.NewScanner(f)
-	for scan.Scan() {
-		line := scan.Text()
-
-		// Split the line at the colon
-		ss := strings.Split(line, ":")
-		if len(ss) != 2 {
-			log.Fatalf("invalid line: %s", line)
-		}
-
-		total, err := strconv.ParseInt(ss[0], 10, 64)
-		if err != nil {
-			log.Fatal(err)
+	for _, r := range SolveAll(eqs, ops, *workers) {
+		if !r.OK {
+			continue
 		}
-
-		// Split the input values
-		ss = strings.Fields(ss[1])
-		inputs := make([]int64, len(ss))
-		for i, s := range ss {
-			inputs[i], err = strconv.ParseInt(s, 10, 64)
-			if err != nil {
-				log.Fatal(err)
-			}
-		}
-
-		if Search(inputs, total) {
-			tot += total
+		if *show {
+			fmt.Println(r.Equation.Format(r.Ops))
 		}
+		tot += r.Equation.Total
 	}
 
 	fmt.Println(tot)
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Equations are evaluated left to right with no precedence, so the search
+// works backward from the total: the last operator has to turn some value
+// into the total using the last input, and each operator knows which values
+// those could be.
+
+// -------------------------------------
+type Operator struct {
+	Symbol string
+
+	// Combine the value so far with the next input. false if the result is
+	// undefined or doesn't fit.
+	Apply func(left, right int64) (int64, bool)
+
+	// The values of left that Apply(left, right) turns into result. If any is
+	// true every left works.
+	Inverse func(result, right int64) (lefts []int64, any bool)
+
+	// Non-negative inputs always give a non-negative result. If every
+	// operator in use does this the search can drop negative targets.
+	NonNegative bool
+}
+
+func (op *Operator) String() string {
+	return op.Symbol
+}
+
+var Add = &Operator{
+	Symbol: "+",
+	Apply: func(left, right int64) (int64, bool) {
+		r := left + right
+		return r, (r > left) == (right > 0) || right == 0
+	},
+	Inverse: func(result, right int64) ([]int64, bool) {
+		l := result - right
+		if (l < result) != (right > 0) && right != 0 {
+			return nil, false
+		}
+		return []int64{l}, false
+	},
+	NonNegative: true,
+}
+
+var Sub = &Operator{
+	Symbol: "-",
+	Apply: func(left, right int64) (int64, bool) {
+		r := left - right
+		return r, (r < left) == (right > 0) || right == 0
+	},
+	Inverse: func(result, right int64) ([]int64, bool) {
+		l := result + right
+		if (l > result) != (right > 0) && right != 0 {
+			return nil, false
+		}
+		return []int64{l}, false
+	},
+}
+
+var Mul = &Operator{
+	Symbol: "*",
+	Apply: func(left, right int64) (int64, bool) {
+		if left == 0 || right == 0 {
+			return 0, true
+		}
+		r := left * right
+		return r, r/right == left && !(left == -1 && right == math.MinInt64) && !(right == -1 && left == math.MinInt64)
+	},
+	Inverse: func(result, right int64) ([]int64, bool) {
+		if right == 0 {
+			return nil, result == 0
+		}
+		if result%right != 0 || (result == math.MinInt64 && right == -1) {
+			return nil, false
+		}
+		return []int64{result / right}, false
+	},
+	NonNegative: true,
+}
+
+// The power of ten with as many digits as v.
+func digitScale(v int64) int64 {
+	p := int64(10)
+	for v >= 10 {
+		v /= 10
+		p *= 10
+	}
+	return p
+}
+
+// Concatenate the digits. Only defined for non-negative numbers.
+var Concat = &Operator{
+	Symbol: "||",
+	Apply: func(left, right int64) (int64, bool) {
+		if left < 0 || right < 0 {
+			return 0, false
+		}
+		p := digitScale(right)
+		if left > (math.MaxInt64-right)/p {
+			return 0, false
+		}
+		return left*p + right, true
+	},
+	Inverse: func(result, right int64) ([]int64, bool) {
+		if result < 0 || right < 0 {
+			return nil, false
+		}
+		p := digitScale(right)
+		if result%p != right {
+			return nil, false
+		}
+		return []int64{result / p}, false
+	},
+	NonNegative: true,
+}
+
+func ipow(base, exp int64) (int64, bool) {
+	switch {
+	case base == 0 && exp > 0:
+		return 0, true
+	case base == 1 || exp == 0:
+		return 1, true
+	case base == -1:
+		return 1 - 2*(exp%2), true
+	}
+
+	r := int64(1)
+	for i := int64(0); i < exp; i++ {
+		next, ok := Mul.Apply(r, base)
+		if !ok {
+			return 0, false
+		}
+		r = next
+	}
+	return r, true
+}
+
+// The largest r >= 0 with r^n <= v, for v >= 0 and n >= 1.
+func iroot(v, n int64) int64 {
+	lo, hi := int64(0), v
+	if n > 1 {
+		hi = min(v, int64(math.Pow(float64(v), 1/float64(n)))+2)
+	}
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		if p, ok := ipow(mid, n); ok && p <= v {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return lo
+}
+
+// Left to right exponent: left raised to right.
+var Pow = &Operator{
+	Symbol: "^",
+	Apply: func(left, right int64) (int64, bool) {
+		if right < 0 {
+			return 0, false
+		}
+		return ipow(left, right)
+	},
+	Inverse: func(result, right int64) ([]int64, bool) {
+		switch {
+		case right < 0:
+			return nil, false
+		case right == 0:
+			return nil, result == 1
+		}
+
+		abs := result
+		if abs < 0 {
+			if right%2 == 0 || result == math.MinInt64 {
+				return nil, false
+			}
+			abs = -abs
+		}
+		r := iroot(abs, right)
+		if p, _ := ipow(r, right); p != abs {
+			return nil, false
+		}
+		switch {
+		case r == 0:
+			return []int64{0}, false
+		case result < 0:
+			return []int64{-r}, false
+		case right%2 == 0:
+			return []int64{r, -r}, false
+		}
+		return []int64{r}, false
+	},
+	NonNegative: true,
+}
+
+// Every operator that can be picked by symbol.
+var Registry = []*Operator{Add, Mul, Concat, Sub, Pow}
+
+func RegisterOperator(op *Operator) {
+	Registry = append(Registry, op)
+}
+
+func LookupOperator(symbol string) (*Operator, error) {
+	for _, op := range Registry {
+		if op.Symbol == symbol {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operator %q", symbol)
+}
+
+// Parse a comma separated list of operator symbols like "+,*,||".
+func ParseOperators(symbols string) ([]*Operator, error) {
+	var ops []*Operator
+	for _, s := range strings.Split(symbols, ",") {
+		op, err := LookupOperator(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// -------------------------------------
+type Equation struct {
+	Total  int64
+	Inputs []int64
+}
+
+func ParseEquation(line string) (Equation, error) {
+	// Split the line at the colon
+	ss := strings.Split(line, ":")
+	if len(ss) != 2 {
+		return Equation{}, fmt.Errorf("invalid line: %s", line)
+	}
+
+	total, err := strconv.ParseInt(ss[0], 10, 64)
+	if err != nil {
+		return Equation{}, err
+	}
+
+	// Split the input values
+	ss = strings.Fields(ss[1])
+	if len(ss) == 0 {
+		return Equation{}, fmt.Errorf("no inputs: %s", line)
+	}
+	inputs := make([]int64, len(ss))
+	for i, s := range ss {
+		inputs[i], err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return Equation{}, err
+		}
+	}
+	return Equation{total, inputs}, nil
+}
+
+func ReadEquations(scan *bufio.Scanner) ([]Equation, error) {
+	var eqs []Equation
+	for scan.Scan() {
+		eq, err := ParseEquation(scan.Text())
+		if err != nil {
+			return nil, err
+		}
+		eqs = append(eqs, eq)
+	}
+	return eqs, scan.Err()
+}
+
+// Evaluate left to right. false if any step is undefined.
+func Evaluate(inputs []int64, ops []*Operator) (int64, bool) {
+	v := inputs[0]
+	for i, op := range ops {
+		var ok bool
+		v, ok = op.Apply(v, inputs[i+1])
+		if !ok {
+			return 0, false
+		}
+	}
+	return v, true
+}
+
+// Find operators that make the inputs come out to total. Returns nil and
+// false if there aren't any.
+func (eq Equation) Solve(ops []*Operator) ([]*Operator, bool) {
+	prune := true
+	for _, op := range ops {
+		prune = prune && op.NonNegative
+	}
+	for _, v := range eq.Inputs {
+		prune = prune && v >= 0
+	}
+
+	var search func(inputs []int64, total int64) ([]*Operator, bool)
+	search = func(inputs []int64, total int64) ([]*Operator, bool) {
+		// Base case: last input stands alone
+		if len(inputs) == 1 {
+			return []*Operator{}, inputs[0] == total
+		}
+		if prune && total < 0 {
+			return nil, false
+		}
+
+		// Pop off the last element
+		a := inputs[:len(inputs)-1]
+		b := inputs[len(inputs)-1]
+
+		for _, op := range ops {
+			lefts, any := op.Inverse(total, b)
+			if any {
+				// Whatever the rest comes to works, as long as it comes to
+				// something.
+				if found, ok := anyValue(a, ops); ok {
+					return append(found, op), true
+				}
+				continue
+			}
+			for _, left := range lefts {
+				if found, ok := search(a, left); ok {
+					return append(found, op), true
+				}
+			}
+		}
+		return nil, false
+	}
+
+	return search(eq.Inputs, eq.Total)
+}
+
+// Find any operators that evaluate without failing.
+func anyValue(inputs []int64, ops []*Operator) ([]*Operator, bool) {
+	var walk func(v int64, rest []int64) ([]*Operator, bool)
+	walk = func(v int64, rest []int64) ([]*Operator, bool) {
+		if len(rest) == 0 {
+			return []*Operator{}, true
+		}
+		for _, op := range ops {
+			next, ok := op.Apply(v, rest[0])
+			if !ok {
+				continue
+			}
+			if found, ok := walk(next, rest[1:]); ok {
+				return append([]*Operator{op}, found...), true
+			}
+		}
+		return nil, false
+	}
+	return walk(inputs[0], inputs[1:])
+}
+
+// Write the equation out with the operators that solve it, like
+// "3267 = 81 + 40 * 27".
+func (eq Equation) Format(ops []*Operator) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d = %d", eq.Total, eq.Inputs[0])
+	for i, op := range ops {
+		fmt.Fprintf(&sb, " %s %d", op.Symbol, eq.Inputs[i+1])
+	}
+	return sb.String()
+}
+
+type Result struct {
+	Equation Equation
+	Ops      []*Operator
+	OK       bool
+}
+
+// Solve every equation across a pool of goroutines. Results are in the same
+// order as the equations.
+func SolveAll(eqs []Equation, ops []*Operator, workers int) []Result {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]Result, len(eqs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				found, ok := eqs[i].Solve(ops)
+				results[i] = Result{eqs[i], found, ok}
+			}
+		}()
+	}
+	for i := range eqs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+type Vector struct {
+	X, Y int
+}
+
+func Add(v1, v2 Vector) Vector {
+	return Vector{v1.X + v2.X, v1.Y + v2.Y}
+}
+
+func Sub(v1, v2 Vector) Vector {
+	return Vector{v1.X - v2.X, v1.Y - v2.Y}
+}
+
+func (v *Vector) GoString() string {
+	return fmt.Sprintf("(%d, %d)", v.X, v.Y)
+}
+
+func gcd(a, b int) int {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// -------------------------------------
+// Which grid positions count as antinodes of a pair of antennas.
+type Mode int
+
+const (
+	// In line with both antennas and twice as far from one as the other.
+	Pairs Mode = iota
+
+	// Anywhere in line with both antennas.
+	Collinear
+)
+
+func ParseMode(s string) (Mode, error) {
+	switch s {
+	case "pairs":
+		return Pairs, nil
+	case "collinear":
+		return Collinear, nil
+	}
+	return 0, fmt.Errorf("unknown mode %q", s)
+}
+
+func (m Mode) String() string {
+	if m == Collinear {
+		return "collinear"
+	}
+	return "pairs"
+}
+
+type Set map[Vector]bool
+
+type Map struct {
+	Size Vector
+
+	// Antenna positions by frequency.
+	Antennas map[rune][]Vector
+}
+
+func ReadMap(scan *bufio.Scanner) (*Map, error) {
+	m := &Map{Antennas: make(map[rune][]Vector)}
+	for scan.Scan() {
+		line := scan.Text()
+		if m.Size.X == 0 {
+			m.Size.X = len(line)
+		} else if len(line) != m.Size.X {
+			return nil, fmt.Errorf("line %d is %d long, want %d", m.Size.Y+1, len(line), m.Size.X)
+		}
+
+		for x, r := range line {
+			if r == '.' || r == '#' {
+				continue
+			}
+			m.Antennas[r] = append(m.Antennas[r], Vector{x, m.Size.Y})
+		}
+		m.Size.Y++
+	}
+	return m, scan.Err()
+}
+
+func (m *Map) InBounds(v Vector) bool {
+	return v.X >= 0 && v.X < m.Size.X && v.Y >= 0 && v.Y < m.Size.Y
+}
+
+// The frequencies in a fixed order.
+func (m *Map) Freqs() []rune {
+	var freqs []rune
+	for r := range m.Antennas {
+		freqs = append(freqs, r)
+	}
+	slices.Sort(freqs)
+	return freqs
+}
+
+// Every antinode of every pair of antennas with the same frequency.
+func (m *Map) Antinodes(mode Mode) Set {
+	antinodes := make(Set)
+	for _, antennas := range m.Antennas {
+		for i, a1 := range antennas {
+			for _, a2 := range antennas[i+1:] {
+				m.pairAntinodes(a1, a2, mode, antinodes)
+			}
+		}
+	}
+	return antinodes
+}
+
+func (m *Map) pairAntinodes(a1, a2 Vector, mode Mode, antinodes Set) {
+	diff := Sub(a2, a1)
+
+	if mode == Pairs {
+		// Outside the pair, and a third of the way in from each end if that
+		// lands on the grid.
+		points := []Vector{Sub(a1, diff), Add(a2, diff)}
+		if diff.X%3 == 0 && diff.Y%3 == 0 {
+			third := Vector{diff.X / 3, diff.Y / 3}
+			points = append(points, Add(a1, third), Sub(a2, third))
+		}
+		for _, p := range points {
+			if m.InBounds(p) {
+				antinodes[p] = true
+			}
+		}
+		return
+	}
+
+	// Step by the smallest offset that stays on the grid, so a difference of
+	// (4, 2) also finds the position at (2, 1).
+	g := gcd(diff.X, diff.Y)
+	step := Vector{diff.X / g, diff.Y / g}
+	for t := a1; m.InBounds(t); t = Add(t, step) {
+		antinodes[t] = true
+	}
+	for t := a1; m.InBounds(t); t = Sub(t, step) {
+		antinodes[t] = true
+	}
+}
+
+// -------------------------------------
+// Draw the map like the puzzle does: antennas by frequency, '#' for an
+// antinode where there isn't an antenna and '.' for everything else.
+func (m *Map) Render(antinodes Set) string {
+	grid := make([][]byte, m.Size.Y)
+	for y := range grid {
+		grid[y] = []byte(strings.Repeat(".", m.Size.X))
+	}
+	for p := range antinodes {
+		grid[p.Y][p.X] = '#'
+	}
+	for freq, antennas := range m.Antennas {
+		for _, a := range antennas {
+			grid[a.Y][a.X] = byte(freq)
+		}
+	}
+
+	var sb strings.Builder
+	for _, row := range grid {
+		sb.Write(row)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func FreqColor(freq rune) string {
+	return fmt.Sprintf("hsl(%d, 65%%, 45%%)", (int(freq)*47)%360)
+}
+
+// Draw antinodes as circles and antennas as their frequency in its own color.
+func (m *Map) WriteSVG(w io.Writer, antinodes Set, scale int) error {
+	if _, err := fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\">\n",
+		m.Size.X*scale, m.Size.Y*scale); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "  <rect width=\"100%%\" height=\"100%%\" fill=\"white\"/>\n"); err != nil {
+		return err
+	}
+
+	points := make([]Vector, 0, len(antinodes))
+	for p := range antinodes {
+		points = append(points, p)
+	}
+	slices.SortFunc(points, func(a, b Vector) int {
+		if a.Y != b.Y {
+			return a.Y - b.Y
+		}
+		return a.X - b.X
+	})
+	for _, p := range points {
+		if _, err := fmt.Fprintf(w, "  <circle cx=\"%d\" cy=\"%d\" r=\"%d\" fill=\"none\" stroke=\"red\"/>\n",
+			p.X*scale+scale/2, p.Y*scale+scale/2, scale*2/5); err != nil {
+			return err
+		}
+	}
+
+	for _, freq := range m.Freqs() {
+		for _, a := range m.Antennas[freq] {
+			if _, err := fmt.Fprintf(w,
+				"  <text x=\"%d\" y=\"%d\" font-size=\"%d\" font-family=\"monospace\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%s\">%c</text>\n",
+				a.X*scale+scale/2, a.Y*scale+scale/2, scale*3/4, FreqColor(freq), freq); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Test every position against every pair straight from the rules.
+func (m *Map) BruteAntinodes(mode Mode) Set {
+	antinodes := make(Set)
+	for y := 0; y < m.Size.Y; y++ {
+		for x := 0; x < m.Size.X; x++ {
+			p := Vector{x, y}
+			for _, antennas := range m.Antennas {
+				for i, a1 := range antennas {
+					for _, a2 := range antennas[i+1:] {
+						d1, d2 := Sub(p, a1), Sub(p, a2)
+						if d1.X*d2.Y-d1.Y*d2.X != 0 {
+							continue
+						}
+						n1, n2 := d1.X*d1.X+d1.Y*d1.Y, d2.X*d2.X+d2.Y*d2.Y
+						if mode == Collinear || n1 == 4*n2 || n2 == 4*n1 {
+							antinodes[p] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	return antinodes
+}
+
+func RandomMap(rng *rand.Rand, size Vector, freqs, perFreq int) *Map {
+	m := &Map{Size: size, Antennas: make(map[rune][]Vector)}
+	taken := make(Set)
+	for f := 0; f < freqs; f++ {
+		freq := rune('a' + f)
+		for i := 0; i < perFreq; i++ {
+			a := Vector{rng.Intn(size.X), rng.Intn(size.Y)}
+			if !taken[a] {
+				taken[a] = true
+				m.Antennas[freq] = append(m.Antennas[freq], a)
+			}
+		}
+	}
+	return m
+}
+
+func sameSet(got, want Set) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for p := range want {
+		if !got[p] {
+			return false
+		}
+	}
+	return true
+}
+
+func checkMap(t *testing.T, m *Map, mode Mode, want Set) {
+	t.Helper()
+	if got := m.Antinodes(mode); !sameSet(got, want) {
+		t.Errorf("%v mode: got %d antinodes, want %d\n%s\n%s", mode, len(got), len(want),
+			m.Render(got), m.Render(want))
+	}
+}
+
+func setOf(points ...Vector) Set {
+	s := make(Set)
+	for _, p := range points {
+		s[p] = true
+	}
+	return s
+}
+
+// Pairs whose offset isn't coprime. Collinear mode has to step by the offset
+// over its GCD, and pairs mode picks up the points a third of the way in when
+// the offset divides by 3.
+func TestNonCoprime(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      Vector
+		a1, a2    Vector
+		pairs     Set
+		collinear Set
+	}{
+		{
+			"offset (4, 2)", Vector{9, 5}, Vector{0, 0}, Vector{4, 2},
+			setOf(Vector{8, 4}),
+			setOf(Vector{0, 0}, Vector{2, 1}, Vector{4, 2}, Vector{6, 3}, Vector{8, 4}),
+		},
+		{
+			"offset (6, 3)", Vector{9, 5}, Vector{0, 0}, Vector{6, 3},
+			setOf(Vector{2, 1}, Vector{4, 2}),
+			setOf(Vector{0, 0}, Vector{2, 1}, Vector{4, 2}, Vector{6, 3}, Vector{8, 4}),
+		},
+		{
+			"offset (6, 3) with room outside", Vector{16, 8}, Vector{3, 1}, Vector{9, 4},
+			setOf(Vector{5, 2}, Vector{7, 3}, Vector{15, 7}),
+			setOf(Vector{1, 0}, Vector{3, 1}, Vector{5, 2}, Vector{7, 3}, Vector{9, 4},
+				Vector{11, 5}, Vector{13, 6}, Vector{15, 7}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Map{Size: tt.size, Antennas: map[rune][]Vector{'a': {tt.a1, tt.a2}}}
+			checkMap(t, m, Pairs, tt.pairs)
+			checkMap(t, m, Collinear, tt.collinear)
+
+			// The brute force has to agree with the expected sets too.
+			if got := m.BruteAntinodes(Pairs); !sameSet(got, tt.pairs) {
+				t.Errorf("brute force pairs: got %v", got)
+			}
+			if got := m.BruteAntinodes(Collinear); !sameSet(got, tt.collinear) {
+				t.Errorf("brute force collinear: got %v", got)
+			}
+		})
+	}
+}
+
+// Compare Antinodes with BruteAntinodes in both modes on random maps.
+func TestAntinodesRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		size := Vector{1 + rng.Intn(15), 1 + rng.Intn(15)}
+		m := RandomMap(rng, size, 1+rng.Intn(3), 2+rng.Intn(4))
+		for _, mode := range []Mode{Pairs, Collinear} {
+			checkMap(t, m, mode, m.BruteAntinodes(mode))
+		}
+	}
+}
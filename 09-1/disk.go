@@ -0,0 +1,189 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type FileID int
+
+const FreeSpace FileID = -1
+
+// A run of blocks that all belong to the same file (or are all free).
+type Extent struct {
+	ID       FileID
+	Location int
+	Length   int
+}
+
+// The checksum contribution of an extent: ID times the sum of its block
+// positions, computed without walking the blocks.
+func (e Extent) Checksum() int {
+	if e.ID == FreeSpace {
+		return 0
+	}
+	return int(e.ID) * (e.Length*e.Location + e.Length*(e.Length-1)/2)
+}
+
+// A disk as parsed from the dense format. Files[i] is file ID i and Free holds
+// the free spans in disk order.
+type Disk struct {
+	Files []Extent
+	Free  []Extent
+	Size  int
+}
+
+func ParseDisk(input string) (*Disk, error) {
+	d := &Disk{}
+	isFileNext := true
+	for _, r := range strings.TrimSpace(input) {
+		if r < '0' || r > '9' {
+			return nil, fmt.Errorf("invalid disk map digit %q", r)
+		}
+		length := int(r - '0')
+
+		if isFileNext {
+			d.Files = append(d.Files, Extent{FileID(len(d.Files)), d.Size, length})
+		} else if length > 0 {
+			d.Free = append(d.Free, Extent{FreeSpace, d.Size, length})
+		}
+		d.Size += length
+		isFileNext = !isFileNext
+	}
+	return d, nil
+}
+
+// The result of compacting: file extents sorted by location. A file may be
+// split over several extents.
+type Layout struct {
+	Extents []Extent
+	Size    int
+}
+
+func (l *Layout) Checksum() int {
+	checksum := 0
+	for _, e := range l.Extents {
+		checksum += e.Checksum()
+	}
+	return checksum
+}
+
+// Render as the "00...111...2" format. IDs past 9 use letters and anything
+// past 61 shows up as '#'.
+func (l *Layout) String() string {
+	const digits = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	blocks := []byte(strings.Repeat(".", l.Size))
+	for _, e := range l.Extents {
+		c := byte('#')
+		if int(e.ID) < len(digits) {
+			c = digits[e.ID]
+		}
+		for i := 0; i < e.Length; i++ {
+			blocks[e.Location+i] = c
+		}
+	}
+	return string(blocks)
+}
+
+// The layout before any compaction.
+func (d *Disk) Layout() *Layout {
+	return &Layout{Extents: slices.Clone(d.Files), Size: d.Size}
+}
+
+func (l *Layout) sort() {
+	slices.SortFunc(l.Extents, func(a, b Extent) int {
+		return a.Location - b.Location
+	})
+}
+
+// Part 1: move blocks one at a time from the end of the disk into the first
+// free block until there are no gaps. Works a span at a time from both ends.
+func (d *Disk) CompactBlocks() *Layout {
+	l := &Layout{Size: d.Size}
+
+	files := slices.Clone(d.Files)
+	free := slices.Clone(d.Free)
+	back := len(files) - 1
+
+	for _, span := range free {
+		for span.Length > 0 && back >= 0 && files[back].Location > span.Location {
+			file := &files[back]
+			n := min(span.Length, file.Length)
+
+			l.Extents = append(l.Extents, Extent{file.ID, span.Location, n})
+			span.Location += n
+			span.Length -= n
+			file.Length -= n
+			if file.Length == 0 {
+				back--
+			}
+		}
+	}
+
+	for _, file := range files[:back+1] {
+		l.Extents = append(l.Extents, file)
+	}
+	l.sort()
+	return l
+}
+
+// A min-heap of free span locations.
+type spanHeap []int
+
+func (h spanHeap) Len() int            { return len(h) }
+func (h spanHeap) Less(i, j int) bool  { return h[i] < h[j] }
+func (h spanHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *spanHeap) Push(x interface{}) { *h = append(*h, x.(int)) }
+func (h *spanHeap) Pop() interface{} {
+	n := len(*h)
+	x := (*h)[n-1]
+	*h = (*h)[:n-1]
+	return x
+}
+
+// Part 2: move whole files, highest ID first, into the leftmost free span
+// that fits. Free spans are never longer than 9 so they are kept in nine
+// min-heaps by length, and the leftmost fit is the smallest top across the
+// heaps that are long enough.
+func (d *Disk) CompactFiles() *Layout {
+	var spans [10]spanHeap
+	for _, span := range d.Free {
+		spans[span.Length] = append(spans[span.Length], span.Location)
+	}
+	for i := range spans {
+		heap.Init(&spans[i])
+	}
+
+	l := &Layout{Size: d.Size}
+	for i := len(d.Files) - 1; i >= 0; i-- {
+		file := d.Files[i]
+		if file.Length == 0 {
+			continue
+		}
+
+		best := -1
+		for length := file.Length; length < len(spans); length++ {
+			if spans[length].Len() == 0 || spans[length][0] > file.Location {
+				continue
+			}
+			if best < 0 || spans[length][0] < spans[best][0] {
+				best = length
+			}
+		}
+
+		if best >= 0 {
+			location := heap.Pop(&spans[best]).(int)
+			file.Location = location
+			if rest := best - file.Length; rest > 0 {
+				heap.Push(&spans[rest], location+file.Length)
+			}
+		}
+		l.Extents = append(l.Extents, file)
+	}
+
+	l.sort()
+	return l
+}
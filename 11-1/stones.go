@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Order doesn't matter for counting stones so the state is a multiset: the
+// count of stones for each value. Counts grow exponentially with the number of
+// blinks so they are big.Ints.
+type Stones struct {
+	Counts map[int]*big.Int
+}
+
+func NewStones() *Stones {
+	return &Stones{Counts: make(map[int]*big.Int)}
+}
+
+func ParseStones(input string) (*Stones, error) {
+	s := NewStones()
+	for _, field := range strings.Fields(input) {
+		v, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		if v < 0 {
+			return nil, fmt.Errorf("stone %d is negative", v)
+		}
+		s.Add(v, big.NewInt(1))
+	}
+	return s, nil
+}
+
+func (s *Stones) Add(value int, count *big.Int) {
+	c, ok := s.Counts[value]
+	if !ok {
+		c = new(big.Int)
+		s.Counts[value] = c
+	}
+	c.Add(c, count)
+}
+
+// What a single stone turns into after one blink. Stones are never negative.
+func BlinkValue(value int) []int {
+	if value < 0 {
+		log.Fatalf("Stone %d is negative", value)
+	}
+	if value == 0 {
+		return []int{1}
+	}
+
+	vText := strconv.Itoa(value)
+	if len(vText)%2 == 0 {
+		s1, err := strconv.Atoi(vText[:len(vText)/2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		s2, err := strconv.Atoi(vText[len(vText)/2:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return []int{s1, s2}
+	}
+
+	if value > math.MaxInt/2024 {
+		log.Fatalf("Stone %d overflows when multiplied", value)
+	}
+	return []int{value * 2024}
+}
+
+func (s *Stones) Blink() *Stones {
+	next := NewStones()
+	for value, count := range s.Counts {
+		for _, v := range BlinkValue(value) {
+			next.Add(v, count)
+		}
+	}
+	return next
+}
+
+func (s *Stones) BlinkN(n int) *Stones {
+	for i := 0; i < n; i++ {
+		s = s.Blink()
+	}
+	return s
+}
+
+// The total number of stones.
+func (s *Stones) Total() *big.Int {
+	total := new(big.Int)
+	for _, count := range s.Counts {
+		total.Add(total, count)
+	}
+	return total
+}
+
+func (s *Stones) Distinct() int {
+	return len(s.Counts)
+}
+
+func (s *Stones) Values() []int {
+	values := make([]int, 0, len(s.Counts))
+	for v := range s.Counts {
+		values = append(values, v)
+	}
+	slices.Sort(values)
+	return values
+}
+
+// Every value reachable from the starting stones in any number of blinks,
+// along with the number of blinks after which nothing new shows up. The set is
+// small and closed, which is why the multiset stays small no matter how many
+// times we blink.
+func (s *Stones) Reachable() (map[int]bool, int) {
+	seen := make(map[int]bool)
+	frontier := s.Values()
+	for _, v := range frontier {
+		seen[v] = true
+	}
+
+	blinks := 0
+	for len(frontier) > 0 {
+		next := []int{}
+		for _, value := range frontier {
+			for _, v := range BlinkValue(value) {
+				if !seen[v] {
+					seen[v] = true
+					next = append(next, v)
+				}
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		frontier = next
+		blinks++
+	}
+	return seen, blinks
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -104,18 +105,29 @@ func (b *Board) GetCost(v Vector) (area, fence int) {
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	b := LoadBoard("input.txt")
+	input := flag.String("input", "input.txt", "garden map to load")
+	svg := flag.String("svg", "", "write an SVG map of the regions to this file")
+	flag.Parse()
+
+	b := LoadBoard(*input)
+	fm := LabelFields(b)
 
 	var cost int
-	for y, row := range b.Cells {
-		for x, cell := range row {
-			if !cell.Visited {
-				area, fence := b.GetCost(Vector{x, y})
-				fmt.Println(area, fence)
-				cost += area * fence
-			}
-		}
+	for _, f := range fm.Fields {
+		fmt.Println(f.Area, f.Perimeter)
+		cost += f.Area * f.Perimeter
 	}
 
 	fmt.Println(cost)
+
+	if *svg != "" {
+		out, err := os.Create(*svg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+		if err := fm.WriteSVG(out, 10); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
@@ -0,0 +1,38 @@
+package main
+
+import "testing"
+
+// Walk each region the old way with GetCost and compare with the labeling.
+// The traced boundary should have a corner for every side.
+func TestCosts(t *testing.T) {
+	for _, fn := range []string{"test.txt"} {
+		b := LoadBoard(fn)
+		fm := LabelFields(b)
+
+		walked := 0
+		for y, row := range b.Cells {
+			for x, cell := range row {
+				if cell.Visited {
+					continue
+				}
+				area, fence := b.GetCost(Vector{x, y})
+				walked++
+
+				f := fm.Fields[fm.Label(Vector{x, y})]
+				if area != f.Area || fence != f.Perimeter {
+					t.Errorf("%s: %c at (%d, %d): area %d, fence %d; labeling has area %d, perimeter %d",
+						fn, f.Crop, x, y, area, fence, f.Area, f.Perimeter)
+				}
+			}
+		}
+		if walked != len(fm.Fields) {
+			t.Errorf("%s: walk found %d regions, labeling found %d", fn, walked, len(fm.Fields))
+		}
+
+		for _, f := range fm.Fields {
+			if corners := fm.Boundary(f).Corners(); corners != f.Sides {
+				t.Errorf("%s: %c region %d: boundary has %d corners, want %d sides", fn, f.Crop, f.ID, corners, f.Sides)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"maps"
@@ -234,20 +235,29 @@ func (b *Board) Walk(v Vector, r *Region) {
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	b := LoadBoard("input.txt")
+	input := flag.String("input", "input.txt", "garden map to load")
+	svg := flag.String("svg", "", "write an SVG map of the regions to this file")
+	flag.Parse()
+
+	b := LoadBoard(*input)
+	fm := LabelFields(b)
 
 	var cost int
-	for y, row := range b.Cells {
-		for x, cell := range row {
-			if !cell.Visited {
-				r := NewRegion(cell.Crop)
-				b.Walk(Vector{x, y}, r)
-				nSides := r.NumSides()
-				fmt.Println(string(r.Crop), r.Area, nSides)
-				cost += r.Area * nSides
-			}
-		}
+	for _, f := range fm.Fields {
+		fmt.Println(string(f.Crop), f.Area, f.Sides)
+		cost += f.Area * f.Sides
 	}
 
 	fmt.Println(cost)
+
+	if *svg != "" {
+		out, err := os.Create(*svg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+		if err := fm.WriteSVG(out, 10); err != nil {
+			log.Fatal(err)
+		}
+	}
 }
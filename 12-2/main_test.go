@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+// Walk each region the old way and compare its area and NumSides with the
+// labeling. The traced boundary should have a corner for every side.
+func TestSides(t *testing.T) {
+	for _, fn := range []string{"test.txt", "test2.txt"} {
+		b := LoadBoard(fn)
+		fm := LabelFields(b)
+
+		walked := 0
+		for y, row := range b.Cells {
+			for x, cell := range row {
+				if cell.Visited {
+					continue
+				}
+				r := NewRegion(cell.Crop)
+				b.Walk(Vector{x, y}, r)
+				walked++
+
+				f := fm.Fields[fm.Label(Vector{x, y})]
+				if r.Area != f.Area || r.NumSides() != f.Sides {
+					t.Errorf("%s: %c at (%d, %d): area %d, NumSides %d; labeling has area %d, sides %d",
+						fn, f.Crop, x, y, r.Area, r.NumSides(), f.Area, f.Sides)
+				}
+			}
+		}
+		if walked != len(fm.Fields) {
+			t.Errorf("%s: walk found %d regions, labeling found %d", fn, walked, len(fm.Fields))
+		}
+
+		for _, f := range fm.Fields {
+			if corners := fm.Boundary(f).Corners(); corners != f.Sides {
+				t.Errorf("%s: %c region %d: boundary has %d corners, want %d sides", fn, f.Crop, f.ID, corners, f.Sides)
+			}
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Region geometry without walking fences. Every region is labeled in one
+// scan with a union-find, then area, perimeter and sides are counted cell by
+// cell. A region has as many sides as it has corners, and each cell can tell
+// which of its own four corners are corners of the region by looking at three
+// neighbors.
+
+// -------------------------------------
+type Field struct {
+	ID    int
+	Crop  rune
+	Cells []Vector
+
+	Area      int
+	Perimeter int
+	Sides     int
+}
+
+type FieldMap struct {
+	Size Vector
+
+	// The field ID of every cell, indexed [y][x].
+	Labels [][]int
+	Fields []*Field
+}
+
+func (fm *FieldMap) Label(v Vector) int {
+	if v.X < 0 || v.Y < 0 || v.X >= fm.Size.X || v.Y >= fm.Size.Y {
+		return -1
+	}
+	return fm.Labels[v.Y][v.X]
+}
+
+func find(parent []int, i int) int {
+	for parent[i] != i {
+		parent[i] = parent[parent[i]]
+		i = parent[i]
+	}
+	return i
+}
+
+// Label every region on the board. Field IDs are given in the order the
+// regions are first seen scanning left to right, top to bottom.
+func LabelFields(b *Board) *FieldMap {
+	fm := &FieldMap{Size: b.Size, Labels: make([][]int, b.Size.Y)}
+
+	crop := func(x, y int) rune { return b.Cells[y][x].Crop }
+	parent := make([]int, b.Size.X*b.Size.Y)
+	for y := 0; y < b.Size.Y; y++ {
+		for x := 0; x < b.Size.X; x++ {
+			i := y*b.Size.X + x
+			parent[i] = i
+			if x > 0 && crop(x-1, y) == crop(x, y) {
+				parent[find(parent, i)] = find(parent, i-1)
+			}
+			if y > 0 && crop(x, y-1) == crop(x, y) {
+				parent[find(parent, i)] = find(parent, i-b.Size.X)
+			}
+		}
+	}
+
+	ids := make(map[int]int)
+	for y := 0; y < b.Size.Y; y++ {
+		fm.Labels[y] = make([]int, b.Size.X)
+		for x := 0; x < b.Size.X; x++ {
+			root := find(parent, y*b.Size.X+x)
+			id, ok := ids[root]
+			if !ok {
+				id = len(fm.Fields)
+				ids[root] = id
+				fm.Fields = append(fm.Fields, &Field{ID: id, Crop: crop(x, y)})
+			}
+			fm.Labels[y][x] = id
+			f := fm.Fields[id]
+			f.Cells = append(f.Cells, Vector{x, y})
+		}
+	}
+
+	for _, f := range fm.Fields {
+		fm.measure(f)
+	}
+	return fm
+}
+
+var diagonals = []Vector{{-1, -1}, {1, -1}, {1, 1}, {-1, 1}}
+
+func (fm *FieldMap) measure(f *Field) {
+	f.Area = len(f.Cells)
+	for _, c := range f.Cells {
+		for _, d := range []Vector{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			if fm.Label(Vector{c.X + d.X, c.Y + d.Y}) != f.ID {
+				f.Perimeter++
+			}
+		}
+
+		// Convex if neither side is in the field, concave if both are but the
+		// diagonal isn't.
+		for _, d := range diagonals {
+			side1 := fm.Label(Vector{c.X + d.X, c.Y}) == f.ID
+			side2 := fm.Label(Vector{c.X, c.Y + d.Y}) == f.ID
+			diag := fm.Label(Vector{c.X + d.X, c.Y + d.Y}) == f.ID
+			if (!side1 && !side2) || (side1 && side2 && !diag) {
+				f.Sides++
+			}
+		}
+	}
+}
+
+// -------------------------------------
+// A closed loop of grid corners. Cell (x, y) covers the square from corner
+// (x, y) to corner (x+1, y+1). Only corners where the loop turns are kept.
+type Polygon []Vector
+
+// Twice the signed area. Outlines go clockwise on screen (y down) so they
+// come out positive and holes come out negative.
+func (p Polygon) Area2() int {
+	var a int
+	for i, v := range p {
+		w := p[(i+1)%len(p)]
+		a += v.X*w.Y - w.X*v.Y
+	}
+	return a
+}
+
+type Boundary struct {
+	Outline Polygon
+	Holes   []Polygon
+}
+
+// One unit fence with the field on its right.
+type fenceEdge struct {
+	From, To Vector
+}
+
+func (e fenceEdge) dir() Vector {
+	return Vector{e.To.X - e.From.X, e.To.Y - e.From.Y}
+}
+
+// Trace the fences around a field into loops. Fences are directed so the
+// field is always on the right. Where two loops touch at a corner the walk
+// turns right so it hugs the field and the loops stay separate.
+func (fm *FieldMap) Boundary(f *Field) Boundary {
+	out := make(map[Vector][]fenceEdge)
+	add := func(from, to Vector) {
+		out[from] = append(out[from], fenceEdge{from, to})
+	}
+	for _, c := range f.Cells {
+		x, y := c.X, c.Y
+		if fm.Label(Vector{x, y - 1}) != f.ID {
+			add(Vector{x, y}, Vector{x + 1, y})
+		}
+		if fm.Label(Vector{x + 1, y}) != f.ID {
+			add(Vector{x + 1, y}, Vector{x + 1, y + 1})
+		}
+		if fm.Label(Vector{x, y + 1}) != f.ID {
+			add(Vector{x + 1, y + 1}, Vector{x, y + 1})
+		}
+		if fm.Label(Vector{x - 1, y}) != f.ID {
+			add(Vector{x, y + 1}, Vector{x, y})
+		}
+	}
+
+	// Pick the fence to follow out of a corner, arriving going in direction d.
+	pick := func(edges []fenceEdge, d Vector) int {
+		right := Vector{-d.Y, d.X}
+		for i, e := range edges {
+			if e.dir() == right {
+				return i
+			}
+		}
+		return 0
+	}
+
+	var b Boundary
+	for _, c := range f.Cells {
+		// Start each loop from a corner that still has a fence leaving it.
+		for _, start := range []Vector{c, {c.X + 1, c.Y}, {c.X + 1, c.Y + 1}, {c.X, c.Y + 1}} {
+			for len(out[start]) > 0 {
+				first := out[start][0]
+				out[start] = out[start][1:]
+
+				loop := Polygon{}
+				e := first
+				for {
+					// Back at the start, the first fence counts as a choice
+					// again in case the loop passes through the start twice.
+					edges := out[e.To]
+					if e.To == start {
+						edges = append([]fenceEdge{first}, edges...)
+					}
+					i := pick(edges, e.dir())
+					next := edges[i]
+					if next.dir() != e.dir() {
+						loop = append(loop, e.To)
+					}
+					if next == first {
+						break
+					}
+					if e.To == start {
+						i--
+					}
+					out[e.To] = append(out[e.To][:i], out[e.To][i+1:]...)
+					e = next
+				}
+
+				if loop.Area2() > 0 {
+					b.Outline = loop
+				} else {
+					b.Holes = append(b.Holes, loop)
+				}
+			}
+		}
+	}
+	return b
+}
+
+// The number of corners over every loop of the boundary, which should be the
+// same as Sides.
+func (b Boundary) Corners() int {
+	n := len(b.Outline)
+	for _, h := range b.Holes {
+		n += len(h)
+	}
+	return n
+}
+
+// -------------------------------------
+// The fill for a crop letter: hues spread around the wheel by letter.
+func CropColor(crop rune) string {
+	hue := (int(crop-'A') * 360 / 26) % 360
+	if hue < 0 {
+		hue += 360
+	}
+	return fmt.Sprintf("hsl(%d, 65%%, 60%%)", hue)
+}
+
+func (p Polygon) svgPath(scale int) string {
+	var sb strings.Builder
+	for i, v := range p {
+		op := "L"
+		if i == 0 {
+			op = "M"
+		}
+		fmt.Fprintf(&sb, "%s%d %d ", op, v.X*scale, v.Y*scale)
+	}
+	sb.WriteString("Z")
+	return sb.String()
+}
+
+// Draw every field as a filled path with its holes cut out, colored by crop.
+func (fm *FieldMap) WriteSVG(w io.Writer, scale int) error {
+	if _, err := fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\">\n",
+		fm.Size.X*scale, fm.Size.Y*scale); err != nil {
+		return err
+	}
+	for _, f := range fm.Fields {
+		b := fm.Boundary(f)
+		paths := []string{b.Outline.svgPath(scale)}
+		for _, h := range b.Holes {
+			paths = append(paths, h.svgPath(scale))
+		}
+		if _, err := fmt.Fprintf(w,
+			"  <path d=\"%s\" fill=\"%s\" fill-rule=\"evenodd\" stroke=\"black\" stroke-width=\"1\"><title>%c %d: area %d, sides %d</title></path>\n",
+			strings.Join(paths, " "), CropColor(f.Crop), f.Crop, f.ID, f.Area, f.Sides); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "</svg>")
+	return err
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// -------------------------------------
+type Machine struct {
+	A, B  Vector
+	Prize Vector
+}
+
+// The buttons as the columns of a matrix so Matrix * presses = Prize.
+func (m Machine) Matrix() Matrix2x2 {
+	return Matrix2x2{m.A.X, m.B.X, m.A.Y, m.B.Y}
+}
+
+// Tokens per press of each button.
+type Costs struct {
+	A, B int
+}
+
+var DefaultCosts = Costs{3, 1}
+
+type Solution struct {
+	A, B int
+	Cost int
+}
+
+func (s Solution) String() string {
+	return fmt.Sprintf("A=%d, B=%d, cost %d", s.A, s.B, s.Cost)
+}
+
+var (
+	buttonARe = regexp.MustCompile(`Button A: X\+(\d*), Y\+(\d*)`)
+	buttonBRe = regexp.MustCompile(`Button B: X\+(\d*), Y\+(\d*)`)
+	prizeRe   = regexp.MustCompile(`Prize: X=(\d*), Y=(\d*)`)
+)
+
+func parseVector(re *regexp.Regexp, line string) (Vector, error) {
+	matches := re.FindStringSubmatch(line)
+	if len(matches) != 3 {
+		return Vector{}, fmt.Errorf("couldn't parse %q", line)
+	}
+	x, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return Vector{}, err
+	}
+	y, err := strconv.Atoi(matches[2])
+	if err != nil {
+		return Vector{}, err
+	}
+	return Vector{x, y}, nil
+}
+
+// Read machines separated by blank lines. offset is added to both
+// coordinates of every prize.
+func ReadMachines(scan *bufio.Scanner, offset int) ([]Machine, error) {
+	var machines []Machine
+	for scan.Scan() {
+		// Line 1 - Button A: X+94, Y+34
+		line1 := scan.Text()
+		if len(line1) == 0 {
+			continue
+		}
+
+		var lines [3]string
+		lines[0] = line1
+		for i := 1; i < 3; i++ {
+			if !scan.Scan() {
+				return nil, fmt.Errorf("couldn't read line %d of machine %d", i+1, len(machines)+1)
+			}
+			lines[i] = scan.Text()
+		}
+
+		var m Machine
+		var err error
+		if m.A, err = parseVector(buttonARe, lines[0]); err != nil {
+			return nil, err
+		}
+		if m.B, err = parseVector(buttonBRe, lines[1]); err != nil {
+			return nil, err
+		}
+		if m.Prize, err = parseVector(prizeRe, lines[2]); err != nil {
+			return nil, err
+		}
+		m.Prize = Vector{m.Prize.X + offset, m.Prize.Y + offset}
+		machines = append(machines, m)
+	}
+	return machines, scan.Err()
+}
+
+// -------------------------------------
+// Returns g = gcd(a, b) along with x and y where a*x + b*y = g.
+func ExtendedGCD(a, b int) (g, x, y int) {
+	if b == 0 {
+		if a < 0 {
+			return -a, -1, 0
+		}
+		return a, 1, 0
+	}
+	g, x1, y1 := ExtendedGCD(b, a%b)
+	return g, y1, x1 - (a/b)*y1
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func ceilDiv(a, b int) int {
+	return -floorDiv(-a, b)
+}
+
+// The cheapest way to win the prize. maxPresses limits each button, or 0 for
+// no limit. Costs must not be negative.
+func (m Machine) Solve(costs Costs, maxPresses int) (Solution, bool) {
+	if m.Matrix().Determinant() != 0 {
+		v, ok := LinearSolve(m.Matrix(), m.Prize)
+		if !ok || !inRange(v.X, maxPresses) || !inRange(v.Y, maxPresses) {
+			return Solution{}, false
+		}
+		return Solution{v.X, v.Y, costs.A*v.X + costs.B*v.Y}, true
+	}
+	return m.solveCollinear(costs, maxPresses)
+}
+
+func inRange(presses, maxPresses int) bool {
+	return presses >= 0 && (maxPresses <= 0 || presses <= maxPresses)
+}
+
+func cross(v1, v2 Vector) int {
+	return v1.X*v2.Y - v1.Y*v2.X
+}
+
+// Both buttons move the claw along the same line. The prize has to be on
+// that line too, and then it is one equation a*p + b*q = r in whichever
+// coordinate the line moves in. Its solutions are a = a0 + t*q/g and
+// b = b0 - t*p/g, and the cost is linear in t so the cheapest is at one end
+// of the range of t that keeps the presses in bounds.
+func (m Machine) solveCollinear(costs Costs, maxPresses int) (Solution, bool) {
+	zero := Vector{}
+	if m.A == zero && m.B == zero {
+		if m.Prize != zero {
+			return Solution{}, false
+		}
+		return Solution{}, true
+	}
+	if cross(m.A, m.Prize) != 0 || cross(m.B, m.Prize) != 0 {
+		return Solution{}, false
+	}
+
+	p, q, r := m.A.X, m.B.X, m.Prize.X
+	if p == 0 && q == 0 {
+		p, q, r = m.A.Y, m.B.Y, m.Prize.Y
+	}
+
+	g, x, y := ExtendedGCD(p, q)
+	if r%g != 0 {
+		return Solution{}, false
+	}
+	a0, b0 := x*(r/g), y*(r/g)
+	da, db := q/g, -p/g
+
+	// Narrow t so that lo <= a0 + t*da <= hi and the same for b.
+	tLo, tHi := math.MinInt, math.MaxInt
+	bound := func(base, step int) bool {
+		hi := math.MaxInt
+		if maxPresses > 0 {
+			hi = maxPresses
+		}
+		switch {
+		case step > 0:
+			tLo = max(tLo, ceilDiv(-base, step))
+			if hi != math.MaxInt {
+				tHi = min(tHi, floorDiv(hi-base, step))
+			}
+		case step < 0:
+			tHi = min(tHi, floorDiv(-base, step))
+			if hi != math.MaxInt {
+				tLo = max(tLo, ceilDiv(hi-base, step))
+			}
+		default:
+			return inRange(base, maxPresses)
+		}
+		return true
+	}
+	if !bound(a0, da) || !bound(b0, db) || tLo > tHi {
+		return Solution{}, false
+	}
+
+	var t int
+	slope := costs.A*da + costs.B*db
+	switch {
+	case slope > 0 && tLo != math.MinInt:
+		t = tLo
+	case slope < 0 && tHi != math.MaxInt:
+		t = tHi
+	case slope == 0 && tLo != math.MinInt:
+		t = tLo
+	case slope == 0 && tHi != math.MaxInt:
+		t = tHi
+	case slope == 0:
+		t = 0
+	default:
+		// Cheaper forever in a direction with no end.
+		return Solution{}, false
+	}
+
+	a, b := a0+t*da, b0+t*db
+	return Solution{a, b, costs.A*a + costs.B*b}, true
+}
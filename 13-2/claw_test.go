@@ -0,0 +1,76 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Try every press count up to maxPresses.
+func BruteSolve(m Machine, costs Costs, maxPresses int) (Solution, bool) {
+	best, found := Solution{}, false
+	for a := 0; a <= maxPresses; a++ {
+		for b := 0; b <= maxPresses; b++ {
+			if m.A.Mul(a).Add(m.B.Mul(b)) != m.Prize {
+				continue
+			}
+			cost := costs.A*a + costs.B*b
+			if !found || cost < best.Cost {
+				best, found = Solution{a, b, cost}, true
+			}
+		}
+	}
+	return best, found
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		m          Machine
+		maxPresses int
+		want       Solution
+		ok         bool
+	}{
+		{"puzzle example", Machine{Vector{94, 34}, Vector{22, 67}, Vector{8400, 5400}}, 100, Solution{80, 40, 280}, true},
+		{"collinear, only B reaches", Machine{Vector{2, 2}, Vector{3, 3}, Vector{3, 3}}, 100, Solution{0, 1, 1}, true},
+		{"collinear, needs both", Machine{Vector{3, 3}, Vector{5, 5}, Vector{11, 11}}, 100, Solution{2, 1, 7}, true},
+		{"collinear, vertical", Machine{Vector{0, 4}, Vector{0, 6}, Vector{0, 14}}, 100, Solution{2, 1, 7}, true},
+		{"collinear, off the line", Machine{Vector{1, 1}, Vector{2, 2}, Vector{3, 4}}, 100, Solution{}, false},
+		{"collinear, over the press limit", Machine{Vector{2, 2}, Vector{3, 3}, Vector{501, 501}}, 100, Solution{}, false},
+		{"collinear, no press limit", Machine{Vector{2, 2}, Vector{3, 3}, Vector{501, 501}}, 0, Solution{0, 167, 167}, true},
+	}
+	for _, tt := range tests {
+		got, ok := tt.m.Solve(DefaultCosts, tt.maxPresses)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s: got %v %v, want %v %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// Compare Solve with BruteSolve on random small machines, half of them with
+// collinear buttons.
+func TestSolveRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		var m Machine
+		m.A = Vector{rng.Intn(10), rng.Intn(10)}
+		if i%2 == 0 {
+			m.B = m.A.Mul(rng.Intn(4))
+			if rng.Intn(2) == 0 {
+				d := Vector{rng.Intn(4), rng.Intn(4)}
+				m.A, m.B = d.Mul(rng.Intn(5)), d.Mul(rng.Intn(5))
+			}
+		} else {
+			m.B = Vector{rng.Intn(10), rng.Intn(10)}
+		}
+		maxPresses := 5 + rng.Intn(30)
+		m.Prize = m.A.Mul(rng.Intn(maxPresses + 5)).Add(m.B.Mul(rng.Intn(maxPresses + 5)))
+		costs := Costs{rng.Intn(5), rng.Intn(5)}
+
+		got, gotOK := m.Solve(costs, maxPresses)
+		want, wantOK := BruteSolve(m, costs, maxPresses)
+		if gotOK != wantOK || (gotOK && got.Cost != want.Cost) {
+			t.Errorf("%+v costs %v max %d: got %v %v, want %v %v",
+				m, costs, maxPresses, got, gotOK, want, wantOK)
+		}
+	}
+}
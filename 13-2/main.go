@@ -2,11 +2,10 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"regexp"
-	"strconv"
 )
 
 // -------------------------------------
@@ -78,106 +77,39 @@ func LinearSolve(a Matrix2x2, c Vector) (Vector, bool) {
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	f, err := os.Open("input.txt")
+	input := flag.String("input", "input.txt", "claw machines to load")
+	offset := flag.Int("offset", 10000000000000, "added to the X and Y of every prize")
+	maxPresses := flag.Int("max-presses", 0, "most times each button can be pressed, 0 for no limit")
+	costA := flag.Int("cost-a", DefaultCosts.A, "tokens to press A")
+	costB := flag.Int("cost-b", DefaultCosts.B, "tokens to press B")
+	flag.Parse()
+
+	f, err := os.Open(*input)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer f.Close()
 
-	var totCost int
-
-	scan := bufio.NewScanner(f)
-	for scan.Scan() {
-		a := Matrix2x2{}
-		c := Vector{}
-
-		// Line 1 - Button A: X+94, Y+34
-		line1 := scan.Text()
-		if len(line1) == 0 {
-			continue
-		}
-
-		re := regexp.MustCompile(`Button A: X\+(\d*), Y\+(\d*)`)
-		matches := re.FindStringSubmatch(line1)
-		if len(matches) != 3 {
-			log.Fatalf("Couldn't parse line 1: %q", line1)
-		}
-
-		i, err := strconv.Atoi(matches[1])
-		if err != nil {
-			log.Fatal(err)
-		}
-		a.A = i
-
-		i, err = strconv.Atoi(matches[2])
-		if err != nil {
-			log.Fatal(err)
-		}
-		a.C = i
-
-		// Line 2 - Button B: X+22, Y+67
-		ok := scan.Scan()
-		if !ok {
-			log.Fatal("Couldn't read line 2")
-		}
-		line2 := scan.Text()
-
-		re = regexp.MustCompile(`Button B: X\+(\d*), Y\+(\d*)`)
-		matches = re.FindStringSubmatch(line2)
-		if len(matches) != 3 {
-			log.Fatalf("Couldn't parse line 2: %q", line2)
-		}
-
-		i, err = strconv.Atoi(matches[1])
-		if err != nil {
-			log.Fatal(err)
-		}
-		a.B = i
-
-		i, err = strconv.Atoi(matches[2])
-		if err != nil {
-			log.Fatal(err)
-		}
-		a.D = i
-
-		// Line 3 - Prize: X=8400, Y=5400
-		ok = scan.Scan()
-		if !ok {
-			log.Fatal("Couldn't read line 3")
-		}
-		line3 := scan.Text()
-
-		re = regexp.MustCompile(`Prize: X=(\d*), Y=(\d*)`)
-		matches = re.FindStringSubmatch(line3)
-		if len(matches) != 3 {
-			log.Fatalf("Couldn't parse line 3: %q", line3)
-		}
-
-		i, err = strconv.Atoi(matches[1])
-		if err != nil {
-			log.Fatal(err)
-		}
-		c.X = i
-
-		i, err = strconv.Atoi(matches[2])
-		if err != nil {
-			log.Fatal(err)
-		}
-		c.Y = i
-
-		c = c.AddInt(10000000000000)
+	machines, err := ReadMachines(bufio.NewScanner(f), *offset)
+	if err != nil {
+		log.Fatal(err)
+	}
 
+	var totCost int
+	costs := Costs{*costA, *costB}
+	for _, m := range machines {
 		// Now solve
-		fmt.Println("A: ", a)
-		fmt.Println("C: ", c)
-		v, ok := LinearSolve(a, c)
+		fmt.Println("A: ", m.Matrix())
+		fmt.Println("C: ", &m.Prize)
+		if m.Matrix().Determinant() == 0 {
+			fmt.Println("Buttons are collinear")
+		}
+		s, ok := m.Solve(costs, *maxPresses)
 		if !ok {
 			fmt.Println("No solution")
 		} else {
-			fmt.Println("Solution: ", v)
-			cost := 3*v.X + v.Y
-			fmt.Println("Cost: ", cost)
-			totCost += cost
+			fmt.Println("Solution: ", s)
+			totCost += s.Cost
 		}
 		fmt.Println()
 	}
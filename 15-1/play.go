@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Interactive mode: drive the robot from the keyboard. Arrow keys or ^v<>
+// move, u undoes, s saves and q quits.
+
+const movesPerLine = 1000
+
+// Write moves the way the puzzle input has them, wrapped at 1000 per line.
+func WriteMoves(w io.Writer, moves []MoveType) error {
+	for i := 0; i < len(moves); i += movesPerLine {
+		var sb strings.Builder
+		for _, m := range moves[i:min(i+movesPerLine, len(moves))] {
+			sb.WriteRune(rune(m))
+		}
+		sb.WriteString("\n")
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Save a puzzle file: the board as it was read followed by the moves, so it
+// can be loaded again with -input.
+func SavePuzzle(path string, boardText string, moves []MoveType) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "%s\n\n", strings.TrimRight(boardText, "\n")); err != nil {
+		return err
+	}
+	if err := WriteMoves(f, moves); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Put the terminal into raw mode with stty. Returns a func that puts it back.
+func rawMode() (func(), error) {
+	stty := func(args ...string) (string, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = os.Stdin
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	saved, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("stdin is not a terminal: %w", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, err
+	}
+	return func() { stty(saved) }, nil
+}
+
+type key int
+
+const (
+	keyNone key = iota
+	keyMove
+	keyUndo
+	keySave
+	keyQuit
+)
+
+// Read one key press. Arrow keys come in as ESC [ A through ESC [ D.
+func readKey(in *bufio.Reader) (key, MoveType, error) {
+	c, err := in.ReadByte()
+	if err != nil {
+		return keyQuit, 0, err
+	}
+
+	switch c {
+	case '^', 'v', '<', '>':
+		return keyMove, MoveType(c), nil
+	case 'u':
+		return keyUndo, 0, nil
+	case 's':
+		return keySave, 0, nil
+	case 'q', 3, 4: // q, Ctrl-C, Ctrl-D
+		return keyQuit, 0, nil
+	case 0x1b:
+		if next, err := in.ReadByte(); err != nil || next != '[' {
+			return keyNone, 0, err
+		}
+		arrow, err := in.ReadByte()
+		if err != nil {
+			return keyNone, 0, err
+		}
+		switch arrow {
+		case 'A':
+			return keyMove, Up, nil
+		case 'B':
+			return keyMove, Down, nil
+		case 'C':
+			return keyMove, Right, nil
+		case 'D':
+			return keyMove, Left, nil
+		}
+	}
+	return keyNone, 0, nil
+}
+
+func redraw(b *Board, status string) {
+	// Raw mode doesn't turn \n into \r\n so do it here.
+	screen := "\x1b[H\x1b[2J" + b.String()
+	screen += fmt.Sprintf("\nScore: %d  Moves: %d\n", b.Score(), len(b.Moves()))
+	screen += "arrows or ^v<> move, u undo, s save, q quit\n"
+	if status != "" {
+		screen += status + "\n"
+	}
+	fmt.Print(strings.ReplaceAll(screen, "\n", "\r\n"))
+}
+
+// Play the board from the keyboard until q is pressed. boardText is the board
+// as read from the input file and is written out on save.
+func Play(b *Board, boardText string, savePath string) error {
+	restore, err := rawMode()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	in := bufio.NewReader(os.Stdin)
+	status := ""
+	for {
+		redraw(b, status)
+		status = ""
+
+		k, move, err := readKey(in)
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		switch k {
+		case keyMove:
+			if !b.MoveRobot(move) {
+				status = "Blocked"
+			}
+		case keyUndo:
+			if !b.Undo() {
+				status = "Nothing to undo"
+			}
+		case keySave:
+			if err := SavePuzzle(savePath, boardText, b.Moves()); err != nil {
+				status = "Save failed: " + err.Error()
+			} else {
+				status = "Saved to " + savePath
+			}
+		case keyQuit:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"log"
+	"strings"
+)
+
+// A warehouse where boxes are objects with any footprint. Part 1 boxes are
+// one cell, part 2 boxes are two cells wide and nothing stops a box from
+// being wider or spanning several rows.
+
+// -------------------------------------
+
+type Cell rune
+
+const InBox Cell = 'O'
+const LBox Cell = '['
+const RBox Cell = ']'
+const MidBox Cell = '='
+const Empty Cell = '.'
+const Wall Cell = '#'
+const Player Cell = '@'
+
+type MoveType rune
+
+const Up MoveType = '^'
+const Down MoveType = 'v'
+const Left MoveType = '<'
+const Right MoveType = '>'
+
+func (m MoveType) Reverse() MoveType {
+	switch m {
+	case Up:
+		return Down
+	case Down:
+		return Up
+	case Left:
+		return Right
+	case Right:
+		return Left
+	}
+	return m
+}
+
+// -------------------------------------
+func MoveVector(v Vector, m MoveType) Vector {
+	switch m {
+	case Up:
+		v.Y--
+	case Down:
+		v.Y++
+	case Left:
+		v.X--
+	case Right:
+		v.X++
+	}
+	return v
+}
+
+// -------------------------------------
+type Box struct {
+	Pos Vector
+
+	// Offsets from Pos of every cell the box covers. Pos is the top left so
+	// all offsets are >= 0.
+	Footprint []Vector
+}
+
+func (bx *Box) Cells() []Vector {
+	cells := make([]Vector, len(bx.Footprint))
+	for i, off := range bx.Footprint {
+		cells[i] = bx.Pos.Add(off)
+	}
+	return cells
+}
+
+// A row of width cells starting at the top left.
+func WideFootprint(width int) []Vector {
+	fp := make([]Vector, width)
+	for i := range fp {
+		fp[i] = Vector{i, 0}
+	}
+	return fp
+}
+
+// One move that was made, kept so it can be undone.
+type Step struct {
+	Move  MoveType
+	Moved bool
+	Boxes []*Box
+}
+
+// -------------------------------------
+type Board struct {
+	Walls [][]bool
+	Boxes []*Box
+	Pos   Vector
+
+	occupied map[Vector]*Box
+	history  []Step
+}
+
+func NewBoard() *Board {
+	return &Board{occupied: make(map[Vector]*Box)}
+}
+
+func (b *Board) IsWall(v Vector) bool {
+	return v.Y < 0 || v.Y >= len(b.Walls) || v.X < 0 || v.X >= len(b.Walls[v.Y]) || b.Walls[v.Y][v.X]
+}
+
+func (b *Board) BoxAt(v Vector) *Box {
+	return b.occupied[v]
+}
+
+func (b *Board) AddBox(pos Vector, footprint []Vector) *Box {
+	bx := &Box{Pos: pos, Footprint: footprint}
+	for _, c := range bx.Cells() {
+		if b.IsWall(c) || b.occupied[c] != nil {
+			log.Fatalf("Box overlaps something at %s", c.String())
+		}
+	}
+	b.Boxes = append(b.Boxes, bx)
+	b.place(bx)
+	return bx
+}
+
+func (b *Board) place(bx *Box) {
+	for _, c := range bx.Cells() {
+		b.occupied[c] = bx
+	}
+}
+
+func (b *Board) lift(bx *Box) {
+	for _, c := range bx.Cells() {
+		delete(b.occupied, c)
+	}
+}
+
+// The character to draw for a cell of a box.
+func (bx *Box) CellRune(c Vector) Cell {
+	left := MoveVector(c, Left)
+	right := MoveVector(c, Right)
+	hasLeft, hasRight := false, false
+	for _, other := range bx.Cells() {
+		hasLeft = hasLeft || other == left
+		hasRight = hasRight || other == right
+	}
+	switch {
+	case hasLeft && hasRight:
+		return MidBox
+	case hasRight:
+		return LBox
+	case hasLeft:
+		return RBox
+	}
+	return InBox
+}
+
+func (b Board) String() string {
+	var sb strings.Builder
+	for y, row := range b.Walls {
+		for x, wall := range row {
+			v := Vector{x, y}
+			switch {
+			case wall:
+				sb.WriteRune(rune(Wall))
+			case v == b.Pos:
+				sb.WriteRune(rune(Player))
+			case b.occupied[v] != nil:
+				sb.WriteRune(rune(b.occupied[v].CellRune(v)))
+			default:
+				sb.WriteRune(rune(Empty))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Read board from scanner up until the first blank line. Every cell is
+// stretched to scale cells wide so an 'O' becomes a box scale cells wide.
+// Boxes already drawn wide with '[', '=' and ']' are read as they are.
+func ReadBoard(scan *bufio.Scanner, scale int) *Board {
+	b := NewBoard()
+
+	// Boxes are added once the walls are all known.
+	type pendingBox struct {
+		Pos   Vector
+		Width int
+	}
+	var boxes []pendingBox
+
+	for scan.Scan() {
+		line := scan.Text()
+		if len(line) == 0 {
+			break
+		}
+
+		y := len(b.Walls)
+		var row []bool
+		boxStart := -1
+		for _, r := range line {
+			x := len(row)
+			switch Cell(r) {
+			case Player:
+				b.Pos = Vector{x, y}
+			case InBox:
+				boxes = append(boxes, pendingBox{Vector{x, y}, scale})
+			case LBox:
+				boxStart = x
+			case RBox:
+				if boxStart < 0 {
+					log.Fatalf("Unmatched ] in board at line %d", y+1)
+				}
+				boxes = append(boxes, pendingBox{Vector{boxStart, y}, x + scale - boxStart})
+				boxStart = -1
+			}
+
+			for i := 0; i < scale; i++ {
+				row = append(row, Cell(r) == Wall)
+			}
+		}
+		b.Walls = append(b.Walls, row)
+	}
+
+	for _, pb := range boxes {
+		b.AddBox(pb.Pos, WideFootprint(pb.Width))
+	}
+	return b
+}
+
+// Work out every box that moves if the robot pushes in a direction. Each box
+// is only looked at once no matter how many cells push on it. Returns false
+// if anything would hit a wall.
+func (b *Board) pushSet(move MoveType) ([]*Box, bool) {
+	boxes := []*Box{}
+	seen := make(map[*Box]bool)
+	frontier := []Vector{MoveVector(b.Pos, move)}
+
+	for len(frontier) > 0 {
+		v := frontier[0]
+		frontier = frontier[1:]
+
+		if b.IsWall(v) {
+			return nil, false
+		}
+		bx := b.occupied[v]
+		if bx == nil || seen[bx] {
+			continue
+		}
+		seen[bx] = true
+		boxes = append(boxes, bx)
+
+		for _, c := range bx.Cells() {
+			next := MoveVector(c, move)
+			if b.occupied[next] != bx {
+				frontier = append(frontier, next)
+			}
+		}
+	}
+	return boxes, true
+}
+
+func (b *Board) shift(boxes []*Box, move MoveType) {
+	for _, bx := range boxes {
+		b.lift(bx)
+	}
+	for _, bx := range boxes {
+		bx.Pos = MoveVector(bx.Pos, move)
+		b.place(bx)
+	}
+}
+
+// Try to move the robot, pushing whatever is in the way. All the boxes move
+// together or not at all. Returns true if the robot moved.
+func (b *Board) MoveRobot(move MoveType) bool {
+	boxes, ok := b.pushSet(move)
+	step := Step{Move: move, Moved: ok, Boxes: boxes}
+	if ok {
+		b.shift(boxes, move)
+		b.Pos = MoveVector(b.Pos, move)
+	}
+	b.history = append(b.history, step)
+	return ok
+}
+
+// Step back one move. Returns false if there is nothing to undo.
+func (b *Board) Undo() bool {
+	if len(b.history) == 0 {
+		return false
+	}
+	step := b.history[len(b.history)-1]
+	b.history = b.history[:len(b.history)-1]
+
+	if step.Moved {
+		back := step.Move.Reverse()
+		b.shift(step.Boxes, back)
+		b.Pos = MoveVector(b.Pos, back)
+	}
+	return true
+}
+
+// The moves made so far, including ones that were blocked.
+func (b *Board) Moves() []MoveType {
+	moves := make([]MoveType, len(b.history))
+	for i, step := range b.history {
+		moves[i] = step.Move
+	}
+	return moves
+}
+
+// Sum of GPS coordinates: 100 times the distance from the top plus the
+// distance from the left, measured to the top left of each box.
+func (b *Board) Score() int {
+	var score int
+	for _, bx := range b.Boxes {
+		score += bx.Pos.X + bx.Pos.Y*100
+	}
+	return score
+}
+
+// -------------------------------------
+
+func ReadMoves(scan *bufio.Scanner) []MoveType {
+	var moves []MoveType
+	for scan.Scan() {
+		line := scan.Text()
+		for _, r := range line {
+			moves = append(moves, MoveType(r))
+		}
+	}
+	return moves
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"container/heap"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
@@ -10,6 +11,7 @@ import (
 	"log"
 	"math"
 	"os"
+	"slices"
 )
 
 // --------------------------------------------------------------------
@@ -155,7 +157,7 @@ func (m Maze) String() string {
 	return s
 }
 
-func (m Maze) SavePNG(curr *Vector, frontiers []*Node) {
+func (m Maze) SavePNG(fn string, curr *Vector, frontiers []*Node) {
 	sizeX := len(m.Cells[0])
 	sizeY := len(m.Cells)
 
@@ -163,13 +165,16 @@ func (m Maze) SavePNG(curr *Vector, frontiers []*Node) {
 
 	for y, row := range m.Cells {
 		for x, cell := range row {
+			// Don't change the cell itself, the solver needs start and end to
+			// be Empty.
+			ct := cell.Type
 			if x == m.Start.X && y == m.Start.Y {
-				cell.Type = Start
+				ct = Start
 			} else if x == m.End.X && y == m.End.Y {
-				cell.Type = End
+				ct = End
 			}
 			var c color.RGBA
-			switch cell.Type {
+			switch ct {
 			case Empty:
 				c = color.RGBA{255, 255, 255, 255}
 				if cell.IsAnyVisited() {
@@ -199,7 +204,7 @@ func (m Maze) SavePNG(curr *Vector, frontiers []*Node) {
 		img.Set(curr.X, curr.Y, color.RGBA{0, 255, 255, 255})
 	}
 
-	f, err := os.Create("debug.png")
+	f, err := os.Create(fn)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -286,7 +291,7 @@ func (m Maze) Solve() int {
 		// Pop the first frontier
 		n := heap.Pop(&pq).(*Node)
 
-		// m.SavePNG(&f.Pos, pq)
+		// m.SavePNG("debug.png", &n.Pos, pq)
 
 		if n.Pos == m.End {
 			return n.Dist
@@ -306,15 +311,11 @@ func (m Maze) Solve() int {
 func (m Maze) MarkPath() {
 	var q []*Node
 
-	// Add all the end nodes
+	// Add the end nodes reached at the lowest cost
 	{
 		endCell := m.At(m.End)
 		endCell.OnPath = true
-		for _, n := range endCell.Nodes {
-			if n.Pos == m.End {
-				q = append(q, n)
-			}
-		}
+		q = append(q, m.EndNodes()...)
 	}
 
 	for len(q) > 0 {
@@ -336,15 +337,33 @@ func (m Maze) MarkPath() {
 	}
 }
 
+// Print each path and, if asked, save it as path-<n>.png.
+func (m Maze) ReportPaths(title string, paths []Path, savePNG bool) {
+	fmt.Printf("%s:\n", title)
+	for i, p := range paths {
+		fmt.Printf("  %d: %s\n", i+1, p)
+		if savePNG {
+			m.MarkCells(p)
+			m.SavePNG(fmt.Sprintf("path-%d.png", i+1), nil, nil)
+		}
+	}
+}
+
 // --------------------------------------------------------------------
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 
-	m := ReadMaze("input.txt")
+	input := flag.String("input", "input.txt", "maze to load")
+	numPaths := flag.Int("paths", 0, "list up to this many minimum cost paths")
+	kth := flag.Int("kth", 0, "list the k cheapest paths, not just the best ones")
+	savePaths := flag.Bool("png", false, "save each listed path as path-<n>.png")
+	flag.Parse()
+
+	m := ReadMaze(*input)
 	fmt.Println(m.Solve())
 	m.MarkPath()
-	m.SavePNG(nil, nil)
+	m.SavePNG("debug.png", nil, nil)
 
 	nPath := 0
 	for _, row := range m.Cells {
@@ -356,4 +375,11 @@ func main() {
 	}
 
 	fmt.Println(nPath)
+
+	if *numPaths > 0 {
+		m.ReportPaths("Best paths", slices.Collect(m.Paths(*numPaths)), *savePaths)
+	}
+	if *kth > 0 {
+		m.ReportPaths("Cheapest paths", m.KShortestPaths(*kth), *savePaths)
+	}
 }
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+)
+
+// --------------------------------------------------------------------
+// Explicit paths through the maze rather than just the cells they cover.
+
+// One node along a path and the number of turns taken to get there.
+type Step struct {
+	Pos   Vector
+	Dir   Dir
+	Turns int
+}
+
+type Path struct {
+	Steps []Step
+	Cost  int
+}
+
+func (p Path) Turns() int {
+	if len(p.Steps) == 0 {
+		return 0
+	}
+	return p.Steps[len(p.Steps)-1].Turns
+}
+
+// The number of distinct tiles the path covers.
+func (p Path) Tiles() int {
+	seen := make(map[Vector]bool)
+	for _, s := range p.Steps {
+		seen[s.Pos] = true
+	}
+	return len(seen)
+}
+
+func (p Path) String() string {
+	return fmt.Sprintf("cost %d, %d turns, %d tiles", p.Cost, p.Turns(), p.Tiles())
+}
+
+func (m Maze) MakePath(nodes []*Node) Path {
+	p := Path{Steps: make([]Step, len(nodes))}
+	turns := 0
+	for i, n := range nodes {
+		if i > 0 {
+			if n.Pos == nodes[i-1].Pos {
+				turns++
+				p.Cost += 1000
+			} else {
+				p.Cost += 1
+			}
+		}
+		p.Steps[i] = Step{n.Pos, n.Dir, turns}
+	}
+	return p
+}
+
+// Mark just the cells on this path.
+func (m Maze) MarkCells(p Path) {
+	for _, row := range m.Cells {
+		for _, cell := range row {
+			cell.OnPath = false
+		}
+	}
+	for _, s := range p.Steps {
+		m.At(s.Pos).OnPath = true
+	}
+}
+
+func (m Maze) StartNode() *Node {
+	return m.At(m.Start).GetNode(Right)
+}
+
+// The end nodes reached at the lowest cost. Only valid after Solve.
+func (m Maze) EndNodes() []*Node {
+	best := math.MaxInt
+	for _, n := range m.At(m.End).Nodes {
+		best = min(best, n.Dist)
+	}
+
+	ret := []*Node{}
+	if best == math.MaxInt {
+		return ret
+	}
+	for _, n := range m.At(m.End).Nodes {
+		if n.Dist == best {
+			ret = append(ret, n)
+		}
+	}
+	return ret
+}
+
+// Every distinct minimum cost path, up to limit of them. Only valid after
+// Solve. Walks back from the end along edges that are tight (From.Dist + Cost
+// == To.Dist) so every branch taken leads back to the start.
+func (m Maze) Paths(limit int) iter.Seq[Path] {
+	return func(yield func(Path) bool) {
+		count := 0
+		working := []*Node{}
+
+		var walk func(n *Node) bool
+		walk = func(n *Node) bool {
+			working = append(working, n)
+			defer func() { working = working[:len(working)-1] }()
+
+			if n.Pos == m.Start && n.Dist == 0 {
+				nodes := slices.Clone(working)
+				slices.Reverse(nodes)
+				count++
+				return yield(m.MakePath(nodes)) && count < limit
+			}
+
+			for _, e := range m.GetInEdges(n) {
+				if e.From.Dist != math.MaxInt && e.From.Dist+e.Cost == n.Dist {
+					if !walk(e.From) {
+						return false
+					}
+				}
+			}
+			return true
+		}
+
+		if limit <= 0 {
+			return
+		}
+		for _, n := range m.EndNodes() {
+			if !walk(n) {
+				return
+			}
+		}
+	}
+}
+
+// --------------------------------------------------------------------
+// k-shortest paths with Yen's algorithm. This needs Dijkstra with nodes and
+// edges taken out so it keeps its own distances rather than using Node.Dist.
+// Yen's runs it once per node of every path found, so the state lives in
+// slices indexed by node and only the entries a search touched are reset.
+
+type distItem struct {
+	Index, Dist int
+}
+
+type distQueue []distItem
+
+func (pq distQueue) Len() int            { return len(pq) }
+func (pq distQueue) Less(i, j int) bool  { return pq[i].Dist < pq[j].Dist }
+func (pq distQueue) Swap(i, j int)       { pq[i], pq[j] = pq[j], pq[i] }
+func (pq *distQueue) Push(x interface{}) { *pq = append(*pq, x.(distItem)) }
+func (pq *distQueue) Pop() interface{} {
+	n := len(*pq)
+	x := (*pq)[n-1]
+	*pq = (*pq)[:n-1]
+	return x
+}
+
+type spurSearch struct {
+	m     Maze
+	width int
+
+	// Every node by index, so the queue can hold plain ints.
+	nodes []*Node
+	dist  []int
+	prev  []*Node
+
+	// An edge is banned by its From node and the direction of its To node,
+	// which is enough to tell the forward move from each turn.
+	bannedNode []bool
+	bannedEdge []bool
+
+	touched     []int
+	bannedNodes []int
+	bannedEdges []int
+	pq          distQueue
+}
+
+func (m Maze) newSpurSearch() *spurSearch {
+	width := len(m.Cells[0])
+	n := width * len(m.Cells) * len(Dirs)
+	s := &spurSearch{
+		m:          m,
+		width:      width,
+		nodes:      make([]*Node, n),
+		dist:       make([]int, n),
+		prev:       make([]*Node, n),
+		bannedNode: make([]bool, n),
+		bannedEdge: make([]bool, n*len(Dirs)),
+	}
+	for i := range s.dist {
+		s.dist[i] = math.MaxInt
+	}
+	for _, row := range m.Cells {
+		for _, cell := range row {
+			for _, node := range cell.Nodes {
+				s.nodes[s.index(node)] = node
+			}
+		}
+	}
+	return s
+}
+
+func (s *spurSearch) index(n *Node) int {
+	return (n.Pos.Y*s.width+n.Pos.X)*len(Dirs) + int(n.Dir)
+}
+
+func (s *spurSearch) banNode(n *Node) {
+	i := s.index(n)
+	s.bannedNode[i] = true
+	s.bannedNodes = append(s.bannedNodes, i)
+}
+
+func (s *spurSearch) banEdge(from, to *Node) {
+	i := s.index(from)*len(Dirs) + int(to.Dir)
+	s.bannedEdge[i] = true
+	s.bannedEdges = append(s.bannedEdges, i)
+}
+
+// Lift every ban, ready for the next spur.
+func (s *spurSearch) unban() {
+	for _, i := range s.bannedNodes {
+		s.bannedNode[i] = false
+	}
+	for _, i := range s.bannedEdges {
+		s.bannedEdge[i] = false
+	}
+	s.bannedNodes = s.bannedNodes[:0]
+	s.bannedEdges = s.bannedEdges[:0]
+}
+
+func (s *spurSearch) reset() {
+	for _, i := range s.touched {
+		s.dist[i] = math.MaxInt
+		s.prev[i] = nil
+	}
+	s.touched = s.touched[:0]
+	s.pq = s.pq[:0]
+}
+
+// Shortest path from a node to any end node avoiding the banned nodes and
+// edges. Returns nil if there isn't one.
+func (s *spurSearch) shortestFrom(from *Node) []*Node {
+	defer s.reset()
+
+	fi := s.index(from)
+	s.dist[fi] = 0
+	s.touched = append(s.touched, fi)
+
+	s.pq = append(s.pq, distItem{fi, 0})
+	for len(s.pq) > 0 {
+		item := heap.Pop(&s.pq).(distItem)
+		ni := item.Index
+		n := s.nodes[ni]
+		if item.Dist > s.dist[ni] {
+			continue
+		}
+
+		if n.Pos == s.m.End {
+			nodes := []*Node{n}
+			for n != from {
+				n = s.prev[s.index(n)]
+				nodes = append(nodes, n)
+			}
+			slices.Reverse(nodes)
+			return nodes
+		}
+
+		// The same edges as GetOutEdges, without allocating them.
+		relax := func(to *Node, cost int) {
+			ti := s.index(to)
+			if s.bannedNode[ti] || s.bannedEdge[ni*len(Dirs)+int(to.Dir)] {
+				return
+			}
+			d := item.Dist + cost
+			if d < s.dist[ti] {
+				if s.dist[ti] == math.MaxInt {
+					s.touched = append(s.touched, ti)
+				}
+				s.dist[ti] = d
+				s.prev[ti] = n
+				heap.Push(&s.pq, distItem{ti, d})
+			}
+		}
+		if next := s.m.At(n.Pos.Add(n.Dir.Vector())); next.Type == Empty {
+			relax(next.GetNode(n.Dir), 1)
+		}
+		for _, d := range n.Dir.Turns() {
+			relax(n.Cell.GetNode(d), 1000)
+		}
+	}
+	return nil
+}
+
+// The k cheapest loopless paths from the start to the end, cheapest first.
+// Ties with the best cost come out before anything more expensive so the
+// first few are the same set Paths would give, maybe in another order.
+func (m Maze) KShortestPaths(k int) []Path {
+	type candidate struct {
+		Nodes []*Node
+		Path  Path
+	}
+
+	search := m.newSpurSearch()
+	first := search.shortestFrom(m.StartNode())
+	if first == nil || k <= 0 {
+		return []Path{}
+	}
+	found := []candidate{{first, m.MakePath(first)}}
+	pending := []candidate{}
+
+	for len(found) < k {
+		last := found[len(found)-1].Nodes
+
+		for i := 0; i < len(last)-1; i++ {
+			spur := last[i]
+			root := last[:i+1]
+
+			for _, c := range found {
+				if len(c.Nodes) > i+1 && slices.Equal(c.Nodes[:i+1], root) {
+					search.banEdge(c.Nodes[i], c.Nodes[i+1])
+				}
+			}
+			for _, n := range root[:i] {
+				search.banNode(n)
+			}
+
+			spurPath := search.shortestFrom(spur)
+			search.unban()
+			if spurPath == nil {
+				continue
+			}
+
+			nodes := append(slices.Clone(root[:i]), spurPath...)
+			dup := slices.ContainsFunc(pending, func(c candidate) bool {
+				return slices.Equal(c.Nodes, nodes)
+			})
+			if !dup {
+				pending = append(pending, candidate{nodes, m.MakePath(nodes)})
+			}
+		}
+
+		if len(pending) == 0 {
+			break
+		}
+
+		best := 0
+		for i, c := range pending {
+			if c.Path.Cost < pending[best].Path.Cost {
+				best = i
+			}
+		}
+		found = append(found, pending[best])
+		pending = slices.Delete(pending, best, best+1)
+	}
+
+	ret := make([]Path, len(found))
+	for i, c := range found {
+		ret[i] = c.Path
+	}
+	return ret
+}
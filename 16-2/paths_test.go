@@ -0,0 +1,42 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKShortestPaths(t *testing.T) {
+	for _, fn := range []string{"test1.txt", "test2.txt"} {
+		m := ReadMaze(fn)
+		best := m.Solve()
+		numBest := len(slices.Collect(m.Paths(100)))
+
+		k := numBest + 5
+		paths := m.KShortestPaths(k)
+		if len(paths) != k {
+			t.Fatalf("%s: got %d paths, want %d", fn, len(paths), k)
+		}
+
+		for i, p := range paths {
+			if i < numBest && p.Cost != best {
+				t.Errorf("%s: path %d costs %d, want the best cost %d", fn, i+1, p.Cost, best)
+			}
+			if i > 0 && p.Cost < paths[i-1].Cost {
+				t.Errorf("%s: path %d costs %d, less than %d before it", fn, i+1, p.Cost, paths[i-1].Cost)
+			}
+			if i >= numBest && p.Cost <= best {
+				t.Errorf("%s: path %d costs %d, want more than the best cost %d", fn, i+1, p.Cost, best)
+			}
+
+			seen := make(map[Step]bool)
+			for _, s := range p.Steps {
+				s.Turns = 0
+				if seen[s] {
+					t.Errorf("%s: path %d visits %v facing %d twice", fn, i+1, s.Pos, s.Dir)
+					break
+				}
+				seen[s] = true
+			}
+		}
+	}
+}
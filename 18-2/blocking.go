@@ -0,0 +1,158 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Three ways to find the first byte that cuts the start off from the end.
+// Each returns the index into events of that byte, or false if the path is
+// never cut.
+
+// Block bytes one at a time and run Dijkstra again whenever the byte lands on
+// a cell the last search visited.
+func FirstBlockingDijkstra(size Vector, events []Vector) (int, bool) {
+	board := NewBoard(size)
+	board.Solve()
+	for i, event := range events {
+		eventCell := board.At(event)
+		eventCell.Blocked = true
+		if eventCell.Score != math.MaxInt {
+			board.Reset()
+			if board.Solve() == math.MaxInt {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Binary search on the number of bytes dropped with a flood fill at each step.
+func FirstBlockingBinarySearch(size Vector, events []Vector) (int, bool) {
+	n := sort.Search(len(events)+1, func(n int) bool {
+		board := NewBoard(size)
+		for _, event := range events[:n] {
+			board.At(event).Blocked = true
+		}
+		return !board.Connected()
+	})
+	if n == 0 || n > len(events) {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+// Drop every byte, then take them away again last first. Each freed cell is
+// joined to its free neighbors and the byte that first connects start and end
+// is the one we want.
+func FirstBlockingUnionFind(size Vector, events []Vector) (int, bool) {
+	board := NewBoard(size)
+	index := func(v Vector) int { return v.Y*size.X + v.X }
+
+	// A byte can land on the same cell more than once. The cell only frees up
+	// once all of them are gone.
+	drops := make([]int, size.X*size.Y)
+	for _, event := range events {
+		board.At(event).Blocked = true
+		drops[index(event)]++
+	}
+
+	uf := NewUnionFind(size.X * size.Y)
+	free := func(v Vector) {
+		board.At(v).Blocked = false
+		for _, neighbor := range v.Neighbors4() {
+			if next := board.At(neighbor); next != nil && !next.Blocked {
+				uf.Union(index(v), index(neighbor))
+			}
+		}
+	}
+	for y := range board.Cells {
+		for x := range board.Cells[y] {
+			if !board.Cells[y][x].Blocked {
+				free(Vector{x, y})
+			}
+		}
+	}
+
+	start, end := index(board.Start), index(board.End)
+	if uf.Connected(start, end) {
+		return 0, false
+	}
+
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		drops[index(event)]--
+		if drops[index(event)] > 0 {
+			continue
+		}
+		free(event)
+		if uf.Connected(start, end) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Flood fill from the start.
+func (b *Board) Connected() bool {
+	if b.At(b.Start).Blocked {
+		return false
+	}
+
+	seen := map[Vector]bool{b.Start: true}
+	q := []Vector{b.Start}
+	for len(q) > 0 {
+		v := q[0]
+		q = q[1:]
+		if v == b.End {
+			return true
+		}
+		for _, neighbor := range v.Neighbors4() {
+			if next := b.At(neighbor); next != nil && !next.Blocked && !seen[neighbor] {
+				seen[neighbor] = true
+				q = append(q, neighbor)
+			}
+		}
+	}
+	return false
+}
+
+// --------------------------------------------------------------------
+// Disjoint sets with path halving and union by size.
+type UnionFind struct {
+	parent []int
+	size   []int
+}
+
+func NewUnionFind(n int) *UnionFind {
+	uf := &UnionFind{make([]int, n), make([]int, n)}
+	for i := range uf.parent {
+		uf.parent[i] = i
+		uf.size[i] = 1
+	}
+	return uf
+}
+
+func (uf *UnionFind) Find(i int) int {
+	for uf.parent[i] != i {
+		uf.parent[i] = uf.parent[uf.parent[i]]
+		i = uf.parent[i]
+	}
+	return i
+}
+
+func (uf *UnionFind) Union(a, b int) {
+	a, b = uf.Find(a), uf.Find(b)
+	if a == b {
+		return
+	}
+	if uf.size[a] < uf.size[b] {
+		a, b = b, a
+	}
+	uf.parent[b] = a
+	uf.size[a] += uf.size[b]
+}
+
+func (uf *UnionFind) Connected(a, b int) bool {
+	return uf.Find(a) == uf.Find(b)
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestFirstBlocking(t *testing.T) {
+	events, err := ReadEvents("test.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, firstBlocking := range impls {
+		i, ok := firstBlocking(Vector{7, 7}, events)
+		if !ok || i != 20 || events[i] != (Vector{6, 1}) {
+			t.Errorf("%s: got event %d (ok %v), want 20 at 6,1", name, i, ok)
+		}
+	}
+}
+
+func benchmarkFirstBlocking(b *testing.B, firstBlocking blockingFunc) {
+	events, err := ReadEvents("input.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		firstBlocking(Vector{71, 71}, events)
+	}
+}
+
+func BenchmarkDijkstra(b *testing.B) {
+	benchmarkFirstBlocking(b, FirstBlockingDijkstra)
+}
+
+func BenchmarkBinarySearch(b *testing.B) {
+	benchmarkFirstBlocking(b, FirstBlockingBinarySearch)
+}
+
+func BenchmarkUnionFind(b *testing.B) {
+	benchmarkFirstBlocking(b, FirstBlockingUnionFind)
+}
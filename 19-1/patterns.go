@@ -0,0 +1,219 @@
+package main
+
+import (
+	"math/big"
+	"math/rand"
+	"slices"
+)
+
+// Matching towel patterns against designs.
+//
+// The patterns are compiled into an Aho–Corasick automaton so a single pass
+// over a design finds every pattern that ends at every index. The number of
+// ways to build a design is then a DP over indices: ways[i] is the number of
+// arrangements of design[:i].
+
+type acNode struct {
+	Next map[byte]int
+	Fail int
+
+	// Lengths of all patterns that end at this node, including ones found
+	// through the fail links.
+	Ends []int
+}
+
+type Patterns struct {
+	nodes []acNode
+	built bool
+
+	Tokens []string
+}
+
+func NewPatterns(tokens ...string) *Patterns {
+	p := &Patterns{
+		nodes: []acNode{{Next: make(map[byte]int)}},
+	}
+	for _, token := range tokens {
+		p.Add(token)
+	}
+	return p
+}
+
+func (p *Patterns) Add(token string) {
+	Assert(!p.built, "Pattern added after the automaton was built")
+	if token == "" {
+		return
+	}
+
+	node := 0
+	for i := 0; i < len(token); i++ {
+		next, ok := p.nodes[node].Next[token[i]]
+		if !ok {
+			next = len(p.nodes)
+			p.nodes = append(p.nodes, acNode{Next: make(map[byte]int)})
+			p.nodes[node].Next[token[i]] = next
+		}
+		node = next
+	}
+
+	if !slices.Contains(p.nodes[node].Ends, len(token)) {
+		p.nodes[node].Ends = append(p.nodes[node].Ends, len(token))
+		p.Tokens = append(p.Tokens, token)
+	}
+}
+
+// Compute the fail links with a BFS from the root. Called on first use.
+func (p *Patterns) build() {
+	if p.built {
+		return
+	}
+	p.built = true
+
+	queue := []int{}
+	for _, child := range p.nodes[0].Next {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for c, child := range p.nodes[node].Next {
+			fail := p.nodes[node].Fail
+			for {
+				if next, ok := p.nodes[fail].Next[c]; ok && next != child {
+					fail = next
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = p.nodes[fail].Fail
+			}
+			p.nodes[child].Fail = fail
+			p.nodes[child].Ends = append(p.nodes[child].Ends, p.nodes[fail].Ends...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+func (p *Patterns) step(node int, c byte) int {
+	for {
+		if next, ok := p.nodes[node].Next[c]; ok {
+			return next
+		}
+		if node == 0 {
+			return 0
+		}
+		node = p.nodes[node].Fail
+	}
+}
+
+// For every index i, the lengths of the patterns that end just before i.
+func (p *Patterns) Matches(design string) [][]int {
+	p.build()
+	ends := make([][]int, len(design)+1)
+	node := 0
+	for i := 0; i < len(design); i++ {
+		node = p.step(node, design[i])
+		ends[i+1] = p.nodes[node].Ends
+	}
+	return ends
+}
+
+// --------------------------------------------------------------------
+// The DP over a single design.
+type Design struct {
+	Design string
+
+	// Ways[i] is the number of ways to build Design[:i].
+	Ways []*big.Int
+
+	ends [][]int
+}
+
+func (p *Patterns) Solve(design string) *Design {
+	d := &Design{
+		Design: design,
+		Ways:   make([]*big.Int, len(design)+1),
+		ends:   p.Matches(design),
+	}
+
+	d.Ways[0] = big.NewInt(1)
+	for i := 1; i <= len(design); i++ {
+		d.Ways[i] = new(big.Int)
+		for _, l := range d.ends[i] {
+			d.Ways[i].Add(d.Ways[i], d.Ways[i-l])
+		}
+	}
+	return d
+}
+
+// The number of arrangements of the whole design.
+func (d *Design) Count() *big.Int {
+	return d.Ways[len(d.Design)]
+}
+
+func (d *Design) Possible() bool {
+	return d.Count().Sign() > 0
+}
+
+// List up to limit arrangements. Only prefixes that can be built are
+// followed so no time is wasted on dead ends.
+func (d *Design) Arrangements(limit int) [][]string {
+	ret := [][]string{}
+	var working []string
+
+	var walk func(end int) bool
+	walk = func(end int) bool {
+		if end == 0 {
+			arrangement := slices.Clone(working)
+			slices.Reverse(arrangement)
+			ret = append(ret, arrangement)
+			return len(ret) < limit
+		}
+		for _, l := range d.ends[end] {
+			if d.Ways[end-l].Sign() == 0 {
+				continue
+			}
+			working = append(working, d.Design[end-l:end])
+			keepGoing := walk(end - l)
+			working = working[:len(working)-1]
+			if !keepGoing {
+				return false
+			}
+		}
+		return true
+	}
+
+	if limit > 0 && d.Possible() {
+		walk(len(d.Design))
+	}
+	return ret
+}
+
+// Pick an arrangement uniformly at random. Walking back from the end, each
+// last pattern is chosen with weight equal to the number of ways to build
+// what is left in front of it.
+func (d *Design) Sample(rng *rand.Rand) []string {
+	if !d.Possible() {
+		return nil
+	}
+
+	ret := []string{}
+	r := new(big.Int)
+	for end := len(d.Design); end > 0; {
+		r.Rand(rng, d.Ways[end])
+		for _, l := range d.ends[end] {
+			w := d.Ways[end-l]
+			if r.Cmp(w) < 0 {
+				ret = append(ret, d.Design[end-l:end])
+				end -= l
+				break
+			}
+			r.Sub(r, w)
+		}
+	}
+	slices.Reverse(ret)
+	return ret
+}
@@ -2,8 +2,10 @@ package main
 
 import (
 	"bufio"
+	"fmt"
 	"log"
 	"os"
+	"runtime/debug"
 	"strconv"
 )
 
@@ -38,3 +40,19 @@ func ReadFileLines(path string) []string {
 
 	return lines
 }
+
+var Debug bool
+
+func DebugLogf(format string, v ...interface{}) {
+	if Debug {
+		s := fmt.Sprintf(format, v...)
+		log.Output(2, s)
+	}
+}
+
+func Assert(cond bool, msg string) {
+	if !cond {
+		debug.PrintStack()
+		log.Fatal(msg)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+)
+
+type Cell struct {
+	Pos         Vector
+	Wall        bool
+	DistToEnd   int
+	DistToStart int
+}
+
+func NewCell(pos Vector, wall bool) Cell {
+	return Cell{pos, wall, math.MaxInt, math.MaxInt}
+}
+
+// --------------------------------------------------------------------
+type Maze struct {
+	Cells [][]Cell
+	Size  Vector
+	Start Vector
+	End   Vector
+}
+
+func NewMaze(lines []string) *Maze {
+	m := Maze{Cells: make([][]Cell, len(lines))}
+	for y, line := range lines {
+		m.Cells[y] = make([]Cell, len(line))
+		for x, r := range line {
+			m.Cells[y][x] = NewCell(Vector{x, y}, r == '#')
+			if r == 'S' {
+				m.Start = Vector{x, y}
+			} else if r == 'E' {
+				m.End = Vector{x, y}
+			}
+		}
+	}
+
+	m.Size = Vector{len(m.Cells[0]), len(m.Cells)}
+	return &m
+}
+
+func (m *Maze) At(pos Vector) *Cell {
+	if pos.IsOOB(m.Size) {
+		return nil
+	}
+	return &m.Cells[pos.Y][pos.X]
+}
+
+func (m *Maze) String() string {
+	var s string
+	for y, row := range m.Cells {
+		for x, cell := range row {
+			if m.Start.X == x && m.Start.Y == y {
+				s += "S"
+			} else if m.End.X == x && m.End.Y == y {
+				s += "E"
+			} else if cell.Wall {
+				s += "#"
+			} else {
+				s += "."
+			}
+		}
+		s += "\n"
+	}
+	return s
+}
+
+// BFS from a cell, storing the distance to every open cell in the field
+// picked by dist.
+func (m *Maze) fill(from Vector, dist func(c *Cell) *int) {
+	*dist(m.At(from)) = 0
+	frontiers := []Vector{from}
+	for len(frontiers) > 0 {
+		frontier := frontiers[0]
+		frontiers = frontiers[1:]
+		for _, neighbor := range frontier.Neighbors4() {
+			next := m.At(neighbor)
+			if next != nil && !next.Wall && *dist(next) == math.MaxInt {
+				*dist(next) = *dist(m.At(frontier)) + 1
+				frontiers = append(frontiers, neighbor)
+			}
+		}
+	}
+}
+
+// Compute the distances from the start and to the end for every cell. Returns
+// the fastest time without cheating.
+func (m *Maze) Solve() int {
+	m.fill(m.End, func(c *Cell) *int { return &c.DistToEnd })
+	m.fill(m.Start, func(c *Cell) *int { return &c.DistToStart })
+	return m.At(m.Start).DistToEnd
+}
+
+// --------------------------------------------------------------------
+// A cheat: leave the track at Start, go through walls for up to the cheat
+// length and come back on at End.
+type Shortcut struct {
+	Start  Vector
+	End    Vector
+	Dist   int
+	Saving int
+}
+
+func (s Shortcut) String() string {
+	return fmt.Sprintf("%v -> %v, Dist: %d, Saving: %d", s.Start, s.End, s.Dist, s.Saving)
+}
+
+// List all the neighbors within dist steps
+func (v Vector) NeighborsX(dist int) []Vector {
+	neighbors := []Vector{}
+
+	for dy := -dist; dy <= dist; dy++ {
+		stepsLeft := dist - AbsInt(dy)
+		for dx := -stepsLeft; dx <= stepsLeft; dx++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			neighbors = append(neighbors, Vector{v.X + dx, v.Y + dy})
+		}
+	}
+
+	return neighbors
+}
+
+// Every cheat of at most cheatMax steps that saves at least minSaving. Solve
+// has to be called first.
+//
+// Nothing here assumes a single track. Any open cell the start can reach can
+// begin a cheat and any open cell that can reach the end can finish one, and
+// the distance fields give the best route either side of it no matter which
+// branch that takes. Each (start, end) pair is one cheat however many routes
+// use it, which is how the puzzle counts them.
+func (m *Maze) Shortcuts(cheatMax int, minSaving int) []Shortcut {
+	fastest := m.At(m.Start).DistToEnd
+	shortcuts := []Shortcut{}
+	if fastest == math.MaxInt {
+		// No route to save time on.
+		return shortcuts
+	}
+	offsets := Vector{}.NeighborsX(cheatMax)
+
+	for y, row := range m.Cells {
+		for x, cell := range row {
+			if cell.Wall || cell.DistToStart == math.MaxInt {
+				continue
+			}
+
+			pos := Vector{x, y}
+			for _, off := range offsets {
+				n := pos.Add(off)
+				end := m.At(n)
+				if end == nil || end.Wall || end.DistToEnd == math.MaxInt {
+					continue
+				}
+
+				dist := cell.DistToStart + pos.ManhattanDist(n) + end.DistToEnd
+				if saving := fastest - dist; saving > 0 && saving >= minSaving {
+					shortcuts = append(shortcuts, Shortcut{pos, n, dist, saving})
+				}
+			}
+		}
+	}
+
+	return shortcuts
+}
+
+// --------------------------------------------------------------------
+// The number of shortcuts for each saving.
+type Histogram map[int]int
+
+func NewHistogram(shortcuts []Shortcut) Histogram {
+	h := make(Histogram)
+	for _, s := range shortcuts {
+		h[s.Saving]++
+	}
+	return h
+}
+
+func (h Histogram) Savings() []int {
+	return slices.Sorted(maps.Keys(h))
+}
+
+func (h Histogram) WriteTable(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%8s %8s\n", "Saving", "Count"); err != nil {
+		return err
+	}
+	for _, saving := range h.Savings() {
+		if _, err := fmt.Fprintf(w, "%8d %8d\n", saving, h[saving]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type histogramEntry struct {
+	Saving int `json:"saving"`
+	Count  int `json:"count"`
+}
+
+// Write as a JSON array of {"saving", "count"} sorted by saving.
+func (h Histogram) WriteJSON(w io.Writer) error {
+	entries := []histogramEntry{}
+	for _, saving := range h.Savings() {
+		entries = append(entries, histogramEntry{saving, h[saving]})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
@@ -62,3 +62,7 @@ func (v Vector) Neighbors4() []Vector {
 		v.Add(Vector{-1, 0}), // Left
 	}
 }
+
+func (v1 Vector) ManhattanDist(v2 Vector) int {
+	return AbsInt(v1.X-v2.X) + AbsInt(v1.Y-v2.Y)
+}
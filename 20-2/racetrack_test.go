@@ -0,0 +1,247 @@
+package main
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// Shortcuts is checked against a brute force on small random mazes. The
+// mazes have branches, dead ends and loops so there is often more than one
+// shortest route, which the puzzle's track never has.
+
+// A random maze: carve a spanning tree with a DFS, then knock out extra
+// walls to add loops. S and E go on random open cells.
+func RandomMaze(rng *rand.Rand, w, h int, loops int) []string {
+	grid := make([][]byte, h)
+	for y := range grid {
+		grid[y] = []byte(strings.Repeat("#", w))
+	}
+
+	// Rooms are on odd coordinates, walls between them.
+	var carve func(x, y int)
+	carve = func(x, y int) {
+		grid[y][x] = '.'
+		for _, i := range rng.Perm(4) {
+			d := []Vector{{0, -2}, {2, 0}, {0, 2}, {-2, 0}}[i]
+			nx, ny := x+d.X, y+d.Y
+			if nx > 0 && ny > 0 && nx < w-1 && ny < h-1 && grid[ny][nx] == '#' {
+				grid[y+d.Y/2][x+d.X/2] = '.'
+				carve(nx, ny)
+			}
+		}
+	}
+	carve(1, 1)
+
+	for i := 0; i < loops; i++ {
+		x, y := 1+rng.Intn(w-2), 1+rng.Intn(h-2)
+		grid[y][x] = '.'
+	}
+
+	open := []Vector{}
+	for y := range grid {
+		for x := range grid[y] {
+			if grid[y][x] == '.' {
+				open = append(open, Vector{x, y})
+			}
+		}
+	}
+	perm := rng.Perm(len(open))
+	s, e := open[perm[0]], open[perm[1]]
+	grid[s.Y][s.X] = 'S'
+	grid[e.Y][e.X] = 'E'
+
+	lines := make([]string, h)
+	for y := range grid {
+		lines[y] = string(grid[y])
+	}
+	return lines
+}
+
+type bruteItem struct {
+	Pos  Vector
+	Dist int
+}
+
+type bruteQueue []bruteItem
+
+func (pq bruteQueue) Len() int            { return len(pq) }
+func (pq bruteQueue) Less(i, j int) bool  { return pq[i].Dist < pq[j].Dist }
+func (pq bruteQueue) Swap(i, j int)       { pq[i], pq[j] = pq[j], pq[i] }
+func (pq *bruteQueue) Push(x interface{}) { *pq = append(*pq, x.(bruteItem)) }
+func (pq *bruteQueue) Pop() interface{} {
+	n := len(*pq)
+	x := (*pq)[n-1]
+	*pq = (*pq)[:n-1]
+	return x
+}
+
+// Shortest route from start to end on the open cells, plus one extra edge
+// from cheatStart to cheatEnd costing cheatLen.
+func (m *Maze) bruteRoute(cheatStart, cheatEnd Vector, cheatLen int) int {
+	dist := map[Vector]int{m.Start: 0}
+	pq := bruteQueue{{m.Start, 0}}
+	for len(pq) > 0 {
+		item := heap.Pop(&pq).(bruteItem)
+		if item.Dist > dist[item.Pos] {
+			continue
+		}
+		if item.Pos == m.End {
+			return item.Dist
+		}
+
+		next := []bruteItem{}
+		for _, n := range item.Pos.Neighbors4() {
+			if c := m.At(n); c != nil && !c.Wall {
+				next = append(next, bruteItem{n, item.Dist + 1})
+			}
+		}
+		if item.Pos == cheatStart {
+			next = append(next, bruteItem{cheatEnd, item.Dist + cheatLen})
+		}
+
+		for _, n := range next {
+			if old, ok := dist[n.Pos]; !ok || n.Dist < old {
+				dist[n.Pos] = n.Dist
+				heap.Push(&pq, n)
+			}
+		}
+	}
+	return math.MaxInt
+}
+
+// The fewest steps from one cell to another ignoring walls, staying on the
+// map.
+func (m *Maze) cheatLen(from, to Vector) int {
+	dist := map[Vector]int{from: 0}
+	q := []Vector{from}
+	for len(q) > 0 {
+		v := q[0]
+		q = q[1:]
+		if v == to {
+			return dist[v]
+		}
+		for _, n := range v.Neighbors4() {
+			if _, ok := dist[n]; !ok && !n.IsOOB(m.Size) {
+				dist[n] = dist[v] + 1
+				q = append(q, n)
+			}
+		}
+	}
+	return math.MaxInt
+}
+
+// Try every (start, end) pair of open cells as a cheat and route through the
+// maze with it. Returns the saving for each pair that saves something.
+func (m *Maze) BruteShortcuts(cheatMax int) map[[2]Vector]int {
+	fastest := m.bruteRoute(m.Start, m.Start, 0)
+	ret := make(map[[2]Vector]int)
+
+	open := []Vector{}
+	for y, row := range m.Cells {
+		for x, cell := range row {
+			if !cell.Wall {
+				open = append(open, Vector{x, y})
+			}
+		}
+	}
+
+	for _, s := range open {
+		for _, e := range open {
+			l := m.cheatLen(s, e)
+			if s == e || l > cheatMax {
+				continue
+			}
+			if saving := fastest - m.bruteRoute(s, e, l); saving > 0 {
+				ret[[2]Vector{s, e}] = saving
+			}
+		}
+	}
+	return ret
+}
+
+// Shortcuts found as a map from (start, end) to saving. Fails on duplicates.
+func shortcutMap(t *testing.T, m *Maze, cheatMax int) map[[2]Vector]int {
+	t.Helper()
+	got := make(map[[2]Vector]int)
+	for _, s := range m.Shortcuts(cheatMax, 1) {
+		key := [2]Vector{s.Start, s.End}
+		if _, ok := got[key]; ok {
+			t.Errorf("duplicate shortcut %v", s)
+		}
+		got[key] = s.Saving
+	}
+	return got
+}
+
+func sameShortcuts(got, want map[[2]Vector]int) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for key, saving := range want {
+		if got[key] != saving {
+			return false
+		}
+	}
+	return true
+}
+
+// A ring with the start and end on either side of a wall, so going round the
+// top and round the bottom are both shortest routes.
+func TestShortcutsTwoRoutes(t *testing.T) {
+	m := NewMaze([]string{
+		"#####",
+		"#...#",
+		"#.#.#",
+		"#S#E#",
+		"#.#.#",
+		"#...#",
+		"#####",
+	})
+	if fastest := m.Solve(); fastest != 6 {
+		t.Fatalf("fastest is %d, want 6", fastest)
+	}
+
+	want := map[[2]Vector]int{
+		{{1, 3}, {3, 3}}: 4,
+		{{1, 2}, {3, 2}}: 2,
+		{{1, 4}, {3, 4}}: 2,
+	}
+	if got := shortcutMap(t, m, 2); !sameShortcuts(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if brute := m.BruteShortcuts(2); !sameShortcuts(brute, want) {
+		t.Errorf("brute force got %v, want %v", brute, want)
+	}
+}
+
+func TestShortcutsNoRoute(t *testing.T) {
+	m := NewMaze([]string{
+		"#####",
+		"#S#E#",
+		"#####",
+	})
+	m.Solve()
+	if got := m.Shortcuts(20, 1); len(got) != 0 {
+		t.Errorf("got %v with no route, want none", got)
+	}
+}
+
+// Compare Shortcuts with BruteShortcuts on random branching mazes.
+func TestShortcutsRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		w, h := 5+2*rng.Intn(5), 5+2*rng.Intn(5)
+		lines := RandomMaze(rng, w, h, rng.Intn(w*h/4+1))
+		cheatMax := []int{2, 3, 6, 20}[rng.Intn(4)]
+
+		m := NewMaze(lines)
+		m.Solve()
+		got := shortcutMap(t, m, cheatMax)
+		if want := m.BruteShortcuts(cheatMax); !sameShortcuts(got, want) {
+			t.Errorf("cheat-max %d: got %d shortcuts, want %d\n%s", cheatMax, len(got), len(want), m)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+)
+
+// Keypad layouts and a solver for a chain of robots typing on them.
+//
+// A layout is parsed from an ASCII diagram with rows separated by '/' or
+// newlines. Every character is a key except ' ', which is a gap that a robot
+// arm may never point at.
+
+type Layout struct {
+	Name  string
+	Keys  map[State]Vector
+	Cells map[Vector]State
+	Size  Vector
+}
+
+var NumericKeypad = MustParseLayout("numeric", "789/456/123/ 0A")
+var DirectionalKeypad = MustParseLayout("directional", " ^A/<v>")
+
+func ParseLayout(name, diagram string) (*Layout, error) {
+	l := &Layout{
+		Name:  name,
+		Keys:  make(map[State]Vector),
+		Cells: make(map[Vector]State),
+	}
+
+	// Split on every separator so an empty row still takes up a row.
+	diagram = strings.TrimSuffix(diagram, "\n")
+	rows := strings.Split(strings.ReplaceAll(diagram, "\n", "/"), "/")
+	for y, row := range rows {
+		for x, r := range []rune(row) {
+			l.Size.X = max(l.Size.X, x+1)
+			if r == ' ' {
+				continue
+			}
+			key := State(r)
+			if _, ok := l.Keys[key]; ok {
+				return nil, fmt.Errorf("layout %s: duplicate key %q", name, r)
+			}
+			pos := Vector{x, y}
+			l.Keys[key] = pos
+			l.Cells[pos] = key
+		}
+	}
+	l.Size.Y = len(rows)
+
+	if _, ok := l.Keys['A']; !ok {
+		return nil, fmt.Errorf("layout %s: no 'A' key to start on", name)
+	}
+	return l, nil
+}
+
+func MustParseLayout(name, diagram string) *Layout {
+	l, err := ParseLayout(name, diagram)
+	Assert(err == nil, fmt.Sprint(err))
+	return l
+}
+
+// Can a robot using this layout drive another robot arm? It needs all of the
+// direction keys along with 'A'.
+func (l *Layout) IsDirectional() bool {
+	for _, a := range Actions {
+		if _, ok := l.Keys[a.State()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// The key reached by moving in a direction from a key, or NULL for a gap or
+// the edge of the keypad.
+func (l *Layout) Next(from State, a Action) State {
+	pos, ok := l.Keys[from]
+	if !ok {
+		return NULL
+	}
+	if next, ok := l.Cells[pos.Neighbors4()[a]]; ok {
+		return next
+	}
+	return NULL
+}
+
+// Convert to the MoveMap form used by Machine.
+func (l *Layout) MoveMap() MoveMap {
+	mm := make(MoveMap)
+	for key := range l.Keys {
+		var moves [4]State
+		for _, a := range Dirs {
+			moves[a] = l.Next(key, a)
+		}
+		mm[key] = moves
+	}
+	return mm
+}
+
+func (l *Layout) String() string {
+	var sb strings.Builder
+	for y := 0; y < l.Size.Y; y++ {
+		for x := 0; x < l.Size.X; x++ {
+			if key, ok := l.Cells[Vector{x, y}]; ok {
+				sb.WriteRune(rune(key))
+			} else {
+				sb.WriteRune(' ')
+			}
+		}
+		sb.WriteRune('\n')
+	}
+	return sb.String()
+}
+
+// --------------------------------------------------------------------
+// A chain of keypads. Layouts[0] is the keypad the code is typed on and every
+// later layout is the keypad used to drive the arm on the one before it. A
+// human presses the keys on the last layout directly.
+type Chain struct {
+	Layouts []*Layout
+
+	// Per layer memo of the best way to move from one key to another and
+	// press it.
+	memo []map[CacheKey]chainStep
+}
+
+type chainStep struct {
+	Cost int
+
+	// Keys pressed on the next layer up to make the move, ending with 'A'.
+	// On the last layer these are the human presses themselves.
+	Keys []State
+}
+
+// Build the puzzle chain: a door keypad driven through a number of robots on
+// directional keypads.
+func NewChain(door *Layout, robots int) *Chain {
+	layouts := []*Layout{door}
+	for i := 0; i < robots; i++ {
+		layouts = append(layouts, DirectionalKeypad)
+	}
+	return NewChainFromLayouts(layouts...)
+}
+
+func NewChainFromLayouts(layouts ...*Layout) *Chain {
+	Assert(len(layouts) > 0, "Chain needs at least one layout")
+	for _, l := range layouts[1:] {
+		Assert(l.IsDirectional(), "Layout "+l.Name+" can't drive a robot")
+	}
+
+	c := &Chain{
+		Layouts: layouts,
+		memo:    make([]map[CacheKey]chainStep, len(layouts)),
+	}
+	for i := range c.memo {
+		c.memo[i] = make(map[CacheKey]chainStep)
+	}
+	return c
+}
+
+type chainItem struct {
+	Pos, Ctrl State
+	Cost      int
+	Done      bool
+	Prev      *chainItem
+}
+
+type chainQueue []*chainItem
+
+func (q chainQueue) Len() int            { return len(q) }
+func (q chainQueue) Less(i, j int) bool  { return q[i].Cost < q[j].Cost }
+func (q chainQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *chainQueue) Push(x interface{}) { *q = append(*q, x.(*chainItem)) }
+func (q *chainQueue) Pop() interface{} {
+	n := len(*q)
+	x := (*q)[n-1]
+	*q = (*q)[:n-1]
+	return x
+}
+
+// The cost in human presses of pressing key `to` on the controlling layer
+// when its arm is on `from`. Past the last layer the human presses directly.
+func (c *Chain) ctrlCost(layer int, from, to State) (int, error) {
+	if layer+1 >= len(c.Layouts) {
+		return 1, nil
+	}
+	s, err := c.step(layer+1, from, to)
+	return s.Cost, err
+}
+
+// Find the cheapest way to move the arm on a layer from one key to another and
+// press it. This is a Dijkstra over (arm position, controller position) where
+// the controller starts and ends on 'A', so it works for any layout with any
+// gaps, not just ones where an L shaped path is always best.
+func (c *Chain) step(layer int, from, to State) (chainStep, error) {
+	key := CacheKey{from, to}
+	if s, ok := c.memo[layer][key]; ok {
+		return s, nil
+	}
+
+	l := c.Layouts[layer]
+	for _, k := range []State{from, to} {
+		if _, ok := l.Keys[k]; !ok {
+			return chainStep{}, fmt.Errorf("key %q is not on the %s keypad", rune(k), l.Name)
+		}
+	}
+
+	type visitKey struct{ Pos, Ctrl State }
+	visited := make(map[visitKey]bool)
+
+	q := chainQueue{{Pos: from, Ctrl: 'A'}}
+	var found *chainItem
+	for len(q) > 0 {
+		item := heap.Pop(&q).(*chainItem)
+		if item.Done {
+			found = item
+			break
+		}
+
+		vk := visitKey{item.Pos, item.Ctrl}
+		if visited[vk] {
+			continue
+		}
+		visited[vk] = true
+
+		if item.Pos == to {
+			cost, err := c.ctrlCost(layer, item.Ctrl, 'A')
+			if err != nil {
+				return chainStep{}, err
+			}
+			heap.Push(&q, &chainItem{
+				Pos:  to,
+				Ctrl: 'A',
+				Cost: item.Cost + cost,
+				Done: true,
+				Prev: item,
+			})
+		}
+
+		for _, a := range Dirs {
+			next := l.Next(item.Pos, a)
+			if next == NULL || visited[visitKey{next, a.State()}] {
+				continue
+			}
+			cost, err := c.ctrlCost(layer, item.Ctrl, a.State())
+			if err != nil {
+				return chainStep{}, err
+			}
+			heap.Push(&q, &chainItem{
+				Pos:  next,
+				Ctrl: a.State(),
+				Cost: item.Cost + cost,
+				Prev: item,
+			})
+		}
+	}
+	if found == nil {
+		return chainStep{}, fmt.Errorf("no path from %s to %s on the %s keypad", from, to, l.Name)
+	}
+
+	s := chainStep{Cost: found.Cost}
+	for item := found; item.Prev != nil; item = item.Prev {
+		s.Keys = append(s.Keys, item.Ctrl)
+	}
+	for i, j := 0, len(s.Keys)-1; i < j; i, j = i+1, j-1 {
+		s.Keys[i], s.Keys[j] = s.Keys[j], s.Keys[i]
+	}
+
+	c.memo[layer][key] = s
+	return s, nil
+}
+
+// The minimum number of human presses to type the code. Every arm starts on
+// 'A'.
+func (c *Chain) Presses(code string) (int, error) {
+	total := 0
+	curr := State('A')
+	for _, r := range code {
+		s, err := c.step(0, curr, State(r))
+		if err != nil {
+			return 0, fmt.Errorf("code %s: %w", code, err)
+		}
+		total += s.Cost
+		curr = State(r)
+	}
+	return total, nil
+}
+
+// The longest press sequence Solve will build.
+const MaxSequenceLen = 1 << 24
+
+// The minimum press count along with a concrete sequence of human presses
+// that achieves it. The sequence grows exponentially with the chain depth so
+// an error is returned if it is longer than MaxSequenceLen.
+func (c *Chain) Solve(code string) (int, string, error) {
+	presses, err := c.Presses(code)
+	if err != nil {
+		return 0, "", err
+	}
+	if presses > MaxSequenceLen {
+		return presses, "", fmt.Errorf("sequence for %s is %d presses, too long to build", code, presses)
+	}
+
+	var sb strings.Builder
+	sb.Grow(presses)
+	curr := State('A')
+	for _, r := range code {
+		c.expand(&sb, 0, curr, State(r))
+		curr = State(r)
+	}
+	Assert(sb.Len() == presses, "Sequence length doesn't match press count")
+	return presses, sb.String(), nil
+}
+
+// Every step was already solved by Presses so this only reads the memo.
+func (c *Chain) expand(sb *strings.Builder, layer int, from, to State) {
+	s := c.memo[layer][CacheKey{from, to}]
+	if layer+1 >= len(c.Layouts) {
+		// The human is pressing direction keys and then 'A'.
+		for _, key := range s.Keys {
+			sb.WriteRune(rune(key))
+		}
+		return
+	}
+
+	curr := State('A')
+	for _, key := range s.Keys {
+		c.expand(sb, layer+1, curr, key)
+		curr = key
+	}
+}
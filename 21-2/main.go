@@ -2,6 +2,7 @@ package main
 
 import (
 	"container/heap"
+	"flag"
 	"fmt"
 	"log"
 	"strconv"
@@ -77,27 +78,6 @@ const NULL State = 0
 
 type MoveMap map[State][4]State
 
-var keypadMoves = MoveMap{
-	'A': {'3', NULL, NULL, '0'},
-	'0': {'2', 'A', NULL, NULL},
-	'1': {'4', '2', NULL, NULL},
-	'2': {'5', '3', '0', '1'},
-	'3': {'6', NULL, 'A', '2'},
-	'4': {'7', '5', '1', NULL},
-	'5': {'8', '6', '2', '4'},
-	'6': {'9', NULL, '3', '5'},
-	'7': {NULL, '8', '4', NULL},
-	'8': {NULL, '9', '5', '7'},
-	'9': {NULL, NULL, '6', '8'},
-}
-var robotMoves = MoveMap{
-	'A': {NULL, NULL, '>', '^'},
-	'<': {NULL, 'v', NULL, NULL},
-	'v': {'^', '>', NULL, '<'},
-	'>': {'A', NULL, NULL, 'v'},
-	'^': {NULL, 'A', 'v', NULL},
-}
-
 // --------------------------------------------------------------------
 type CacheKey struct {
 	From State
@@ -179,14 +159,21 @@ func (m *Machine) Press(from, to State) int {
 	heap.Push(&frontiers, qi)
 	m.DebugLogf("%s -> %s Pushing: %s\n", from, to, qi)
 
-	visited := make(map[State]bool)
-	visited[from] = true
+	// Where the parent is matters as much as where we are, so track both.
+	type visitKey struct{ S, ParentS State }
+	visited := make(map[visitKey]bool)
 
 	for len(frontiers) > 0 {
 		frontier := heap.Pop(&frontiers).(*QueueItem)
 		m.DebugLogf("%s -> %s Popping: %s\n", from, to, frontier)
 
-		visited[frontier.S] = true
+		if !frontier.Pressing {
+			vk := visitKey{frontier.S, frontier.ParentS}
+			if visited[vk] {
+				continue
+			}
+			visited[vk] = true
+		}
 
 		if frontier.S == to {
 			if frontier.Pressing {
@@ -216,7 +203,7 @@ func (m *Machine) Press(from, to State) int {
 
 		nextStates := m.Moves[frontier.S]
 		for i, next := range nextStates {
-			if next == NULL || visited[next] {
+			if next == NULL {
 				continue
 			}
 
@@ -250,18 +237,48 @@ func (m *Machine) Press(from, to State) int {
 
 // --------------------------------------------------------------------
 
-const NumLayers = 26
+// Build a Machine chain matching a keypad Chain.
+func NewMachineChain(c *Chain) *Machine {
+	keypad := NewMachine("kp", 0, c.Layouts[0].MoveMap())
+	m := keypad
+	for i, l := range c.Layouts[1:] {
+		robot := NewMachine("m"+strconv.Itoa(i), i+1, l.MoveMap())
+		m.Parent = robot
+		m = robot
+	}
+	return keypad
+}
+
+// Minimum presses for a code using the Machine search.
+func (m *Machine) Presses(code string) int {
+	totalDist := 0
+	currDigit := State('A')
+	for _, nextRune := range code {
+		nextDigit := State(nextRune)
+		dist := m.Press(currDigit, nextDigit)
+		m.DebugLogf("Pressing %s -> %s: %d\n", currDigit, nextDigit, dist)
+		totalDist += dist
+		currDigit = nextDigit
+	}
+	return totalDist
+}
 
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	Debug = false
 	timeStart := time.Now()
 
+	test := flag.Bool("test", false, "use the example codes")
+	robots := flag.Int("robots", 25, "number of robots on directional keypads")
+	door := flag.String("door", "789/456/123/ 0A", "layout of the door keypad")
+	solver := flag.String("solver", "chain", "solver to use: chain or machine")
+	simulate := flag.String("simulate", "", "run a press sequence through the chain and print what it types")
+	flag.Parse()
+
 	var codes []string
 
 	// Input date
-	test := false
-	if test {
+	if *test {
 		codes = []string{
 			"029A",
 			"980A",
@@ -279,30 +296,44 @@ func main() {
 		}
 	}
 
-	// Build machine chain
-	keypad := NewMachine("kp", 0, keypadMoves)
-	{
-		c := keypad
-		for i := 0; i < NumLayers-1; i++ {
-			robot := NewMachine("m"+strconv.Itoa(i), i+1, robotMoves)
-			c.Parent = robot
-			c = robot
+	doorLayout, err := ParseLayout("door", *door)
+	if err != nil {
+		log.Fatal(err)
+	}
+	chain := NewChain(doorLayout, *robots)
+	machine := NewMachineChain(chain)
+
+	if *simulate != "" {
+		typed, err := NewChainSimulator(chain).Run(*simulate)
+		fmt.Printf("Typed: %q\n", typed)
+		if err != nil {
+			fmt.Println("Error:", err)
 		}
+		return
 	}
 
 	complexity := 0
 
 	for _, code := range codes {
-		totalDist := 0
-
-		currDigit := State('A')
-		for _, nextRune := range code {
-			nextDigit := State(nextRune)
-			dist := keypad.Press(currDigit, nextDigit)
-			fmt.Printf("Pressing %s -> %s: %d\n", currDigit, nextDigit, dist)
-			totalDist += dist // Account for pressing A
-			currDigit = nextDigit
+		// This also checks that every key in the code is on the door keypad.
+		totalDist, err := chain.Presses(code)
+		if err != nil {
+			log.Fatal(err)
 		}
+
+		switch *solver {
+		case "chain":
+			// Deep chains give sequences too long to build, so only print
+			// the ones that fit.
+			if _, seq, err := chain.Solve(code); err == nil {
+				fmt.Printf("Code: %s, Sequence: %s\n", code, seq)
+			}
+		case "machine":
+			totalDist = machine.Presses(code)
+		default:
+			log.Fatalf("Unknown solver %q", *solver)
+		}
+
 		fmt.Printf("Code: %s, Dist: %d\n", code, totalDist)
 		currComplexity := totalDist * MustAtoi(code[0:3])
 		complexity += currComplexity
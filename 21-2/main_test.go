@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var exampleCodes = []string{"029A", "980A", "179A", "456A", "379A"}
+
+// Brute force BFS over the position of every arm along with how much of the
+// code is typed. Only practical for shallow chains but it makes no
+// assumptions, so it is a good check on the other solvers.
+func BFSPresses(code string, moves ...MoveMap) int {
+	type bfsState struct {
+		Pos   string
+		Typed int
+	}
+
+	start := bfsState{strings.Repeat("A", len(moves)), 0}
+	dist := map[bfsState]int{start: 0}
+	queue := []bfsState{start}
+
+	for len(queue) > 0 {
+		curr := queue[0]
+		queue = queue[1:]
+		if curr.Typed == len(code) {
+			return dist[curr]
+		}
+
+		for _, a := range Actions {
+			sim := NewSimulator(moves...)
+			for i, r := range curr.Pos {
+				sim.Pos[i] = State(r)
+			}
+
+			typed, err := sim.Press(0, a.State())
+			if err != nil {
+				continue
+			}
+
+			next := bfsState{Typed: curr.Typed}
+			if typed != NULL {
+				if typed != State(code[curr.Typed]) {
+					continue
+				}
+				next.Typed++
+			}
+
+			var sb strings.Builder
+			for _, p := range sim.Pos {
+				sb.WriteRune(rune(p))
+			}
+			next.Pos = sb.String()
+
+			if _, ok := dist[next]; !ok {
+				dist[next] = dist[curr] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return -1
+}
+
+// Solve every example code on chains of 0..3 robots with the chain solver, the
+// Machine search and the BFS, and check that the chain's press sequence types
+// the code when simulated.
+func TestCheckSolvers(t *testing.T) {
+	for robots := 0; robots <= 3; robots++ {
+		chain := NewChain(NumericKeypad, robots)
+		machine := NewMachineChain(chain)
+		moves := NewChainSimulator(chain).Moves
+
+		for _, code := range exampleCodes {
+			presses, seq, err := chain.Solve(code)
+			if err != nil {
+				t.Errorf("robots=%d %s: %v", robots, code, err)
+				continue
+			}
+			if len(seq) != presses {
+				t.Errorf("robots=%d %s: sequence is %d long, want %d", robots, code, len(seq), presses)
+			}
+			if typed, err := Simulate(seq, moves...); err != nil || typed != code {
+				t.Errorf("robots=%d %s: sequence typed %q, err %v", robots, code, typed, err)
+			}
+			if got := machine.Presses(code); got != presses {
+				t.Errorf("robots=%d %s: machine=%d, chain=%d", robots, code, got, presses)
+			}
+			if got := BFSPresses(code, moves...); got != presses {
+				t.Errorf("robots=%d %s: bfs=%d, chain=%d", robots, code, got, presses)
+			}
+		}
+	}
+}
+
+// The press counts from the puzzle for two robots.
+func TestExamplePresses(t *testing.T) {
+	want := map[string]int{"029A": 68, "980A": 60, "179A": 68, "456A": 64, "379A": 64}
+	chain := NewChain(NumericKeypad, 2)
+	for code, w := range want {
+		if got, err := chain.Presses(code); err != nil || got != w {
+			t.Errorf("%s: got %d, %v, want %d", code, got, err, w)
+		}
+	}
+}
+
+func TestSimulateGap(t *testing.T) {
+	// From 'A' on the door keypad, left to '0' and then left again is the gap.
+	_, err := Simulate("<<", NumericKeypad.MoveMap())
+	var illegal *IllegalMoveError
+	if !errors.As(err, &illegal) || illegal.Index != 1 || illegal.From != '0' || illegal.Move != Left {
+		t.Errorf("got %v, want an illegal move left from 0 at press 1", err)
+	}
+}
+
+func TestUnknownKey(t *testing.T) {
+	door := MustParseLayout("door", "123/ 0A")
+	if _, err := NewChain(door, 2).Presses("029A"); err == nil {
+		t.Error("got no error for a code with keys that aren't on the keypad")
+	}
+}
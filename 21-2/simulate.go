@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Forward simulation of a robot chain. This is used to check that a press
+// sequence really types the code and never points an arm at a gap.
+
+// The arm on a layer tried to move off the keypad or over a gap.
+type IllegalMoveError struct {
+	Index int // Index into the press string
+	Layer int
+	From  State
+	Move  Action
+}
+
+func (e *IllegalMoveError) Error() string {
+	return fmt.Sprintf("press %d: arm on layer %d can't move %s from %s", e.Index, e.Layer, e.Move, e.From)
+}
+
+type Simulator struct {
+	// Moves[0] is the door keypad. The human presses keys that drive the arm
+	// on the last layer.
+	Moves []MoveMap
+	Pos   []State
+	Typed strings.Builder
+}
+
+func NewSimulator(moves ...MoveMap) *Simulator {
+	s := &Simulator{
+		Moves: moves,
+		Pos:   make([]State, len(moves)),
+	}
+	for i := range s.Pos {
+		s.Pos[i] = 'A'
+	}
+	return s
+}
+
+func NewChainSimulator(c *Chain) *Simulator {
+	moves := make([]MoveMap, len(c.Layouts))
+	for i, l := range c.Layouts {
+		moves[i] = l.MoveMap()
+	}
+	return NewSimulator(moves...)
+}
+
+// Run one human press through the chain. Returns the key typed on the door
+// keypad or NULL if nothing was typed.
+func (s *Simulator) Press(index int, key State) (State, error) {
+	for layer := len(s.Pos) - 1; layer >= 0; layer-- {
+		action := key.Action()
+		if action == Invalid {
+			return NULL, fmt.Errorf("press %d: %q isn't a direction or A", index, rune(key))
+		}
+
+		if action != PressOld {
+			next := s.Moves[layer][s.Pos[layer]][action]
+			if next == NULL {
+				return NULL, &IllegalMoveError{index, layer, s.Pos[layer], action}
+			}
+			s.Pos[layer] = next
+			return NULL, nil
+		}
+
+		// Pressing A on this layer presses whatever the arm is on.
+		key = s.Pos[layer]
+	}
+
+	s.Typed.WriteRune(rune(key))
+	return key, nil
+}
+
+// Run a full press string and return what was typed on the door keypad. On an
+// illegal move the output typed so far is returned along with the error.
+func (s *Simulator) Run(presses string) (string, error) {
+	for i, r := range presses {
+		if _, err := s.Press(i, State(r)); err != nil {
+			return s.Typed.String(), err
+		}
+	}
+	return s.Typed.String(), nil
+}
+
+func Simulate(presses string, moves ...MoveMap) (string, error) {
+	return NewSimulator(moves...).Run(presses)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"math"
+	"math/bits"
+)
+
+// The mix and prune steps in Generate are all shifts, XORs and masks so the
+// whole step is linear over GF(2). That means it is a 24x24 bit matrix and N
+// steps is just that matrix raised to the Nth power.
+
+const SecretBits = 24
+const SecretMask = 1<<SecretBits - 1
+
+// BitMatrix is a SecretBits x SecretBits matrix over GF(2). Column j is the
+// image of the secret with only bit j set.
+type BitMatrix [SecretBits]uint32
+
+func IdentityMatrix() BitMatrix {
+	var m BitMatrix
+	for j := range m {
+		m[j] = 1 << j
+	}
+	return m
+}
+
+// Build the matrix for a linear function by feeding it each basis vector.
+func MatrixOf(f func(int) int) BitMatrix {
+	var m BitMatrix
+	for j := range m {
+		m[j] = uint32(f(1 << j))
+	}
+	return m
+}
+
+func (m BitMatrix) Apply(v uint32) uint32 {
+	var ret uint32
+	for v != 0 {
+		j := bits.TrailingZeros32(v)
+		ret ^= m[j]
+		v &= v - 1
+	}
+	return ret
+}
+
+// Return m * o -- that is apply o first and then m.
+func (m BitMatrix) Mul(o BitMatrix) BitMatrix {
+	var ret BitMatrix
+	for j := range o {
+		ret[j] = m.Apply(o[j])
+	}
+	return ret
+}
+
+// Invert with Gauss-Jordan elimination. Returns false if m is singular.
+func (m BitMatrix) Inverse() (BitMatrix, bool) {
+	// Work on rows: row r of m in the low bits, row r of the identity above.
+	var rows [SecretBits]uint64
+	for r := range rows {
+		var row uint64
+		for j, col := range m {
+			row |= uint64(col>>r&1) << j
+		}
+		rows[r] = row | 1<<(SecretBits+r)
+	}
+
+	for c := 0; c < SecretBits; c++ {
+		pivot := -1
+		for r := c; r < SecretBits; r++ {
+			if rows[r]>>c&1 != 0 {
+				pivot = r
+				break
+			}
+		}
+		if pivot < 0 {
+			return BitMatrix{}, false
+		}
+		rows[c], rows[pivot] = rows[pivot], rows[c]
+		for r := range rows {
+			if r != c && rows[r]>>c&1 != 0 {
+				rows[r] ^= rows[c]
+			}
+		}
+	}
+
+	var inv BitMatrix
+	for r, row := range rows {
+		for j := range inv {
+			inv[j] |= uint32(row>>(SecretBits+j)&1) << r
+		}
+	}
+	return inv, true
+}
+
+// ----------------------------------------------------------------------------
+type Generator struct {
+	Step    BitMatrix
+	Inverse BitMatrix
+
+	// powers[k] is Step^(2^k) and inversePowers[k] is Inverse^(2^k)
+	powers        [63]BitMatrix
+	inversePowers [63]BitMatrix
+}
+
+func NewGenerator() *Generator {
+	g := &Generator{
+		Step: MatrixOf(Generate),
+	}
+
+	inv, ok := g.Step.Inverse()
+	Assert(ok, "Secret step is not invertible")
+	g.Inverse = inv
+
+	g.powers[0] = g.Step
+	g.inversePowers[0] = g.Inverse
+	for k := 1; k < len(g.powers); k++ {
+		g.powers[k] = g.powers[k-1].Mul(g.powers[k-1])
+		g.inversePowers[k] = g.inversePowers[k-1].Mul(g.inversePowers[k-1])
+	}
+	return g
+}
+
+// Jump n steps forward in O(log n). A negative n steps backwards.
+func (g *Generator) Jump(secret int, n int) int {
+	if n == math.MinInt {
+		// -n doesn't fit, so take the last step back on its own.
+		return g.Prev(g.Jump(secret, n+1))
+	}
+
+	powers := &g.powers
+	if n < 0 {
+		powers = &g.inversePowers
+		n = -n
+	}
+
+	v := uint32(secret & SecretMask)
+	for k := 0; n != 0; k++ {
+		if n&1 != 0 {
+			v = powers[k].Apply(v)
+		}
+		n >>= 1
+	}
+	return int(v)
+}
+
+// The secret that produced this one.
+func (g *Generator) Prev(secret int) int {
+	return int(g.Inverse.Apply(uint32(secret & SecretMask)))
+}
+
+// The matrix for n steps.
+func (g *Generator) Matrix(n int) BitMatrix {
+	Assert(n >= 0, "Matrix power must not be negative")
+	m := IdentityMatrix()
+	for k := 0; n != 0; k++ {
+		if n&1 != 0 {
+			m = g.powers[k].Mul(m)
+		}
+		n >>= 1
+	}
+	return m
+}
+
+// The step is a permutation so every secret is on a pure cycle. Walk it to
+// find its length.
+func (g *Generator) CycleLength(secret int) int {
+	start := secret & SecretMask
+	s := start
+	for n := 1; ; n++ {
+		s = Generate(s)
+		if s == start {
+			return n
+		}
+	}
+}
+
+// The period of the generator as a whole: the smallest n where Step^n is the
+// identity. That is the lcm of the cycle lengths of the basis vectors. Most of
+// the time the first cycle already works for all of them so only the ones that
+// don't come back are walked.
+func (g *Generator) Period() int {
+	period := 1
+	for j := 0; j < SecretBits; j++ {
+		if g.Jump(1<<j, period) == 1<<j {
+			continue
+		}
+		l := g.CycleLength(1 << j)
+		period = period / gcd(period, l) * l
+	}
+	return period
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"time"
@@ -33,21 +34,36 @@ func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	timeStart := time.Now()
 
+	steps := flag.Int("steps", 2000, "number of secrets to generate per buyer")
+	origin := flag.Int("origin", -1, "print the secret that produced this one after -steps steps and exit")
+	period := flag.Bool("period", false, "also print the period of the generator")
+	flag.Parse()
+
+	gen := NewGenerator()
+
+	if *origin >= 0 {
+		fmt.Println("Origin:", gen.Jump(*origin, -*steps))
+		return
+	}
+
 	lines := ReadFileLines("input.txt")
 	var inputs []int
 	for _, line := range lines {
 		inputs = append(inputs, MustAtoi(line))
 	}
-	_ = inputs
 
 	sum := 0
 	for _, i := range inputs {
 		DebugLogf("%d: ", i)
-		i = GenerateN(i, 2000)
-		DebugLogf("%d\n", i)
-		sum += i
+		out := gen.Jump(i, *steps)
+		DebugLogf("%d\n", out)
+		Assert(gen.Jump(out, -*steps) == i, "Stepping back did not return to the input")
+		sum += out
 	}
 	fmt.Println("Sum:", sum)
+	if *period {
+		fmt.Println("Period:", gen.Period())
+	}
 
 	fmt.Println("Elapsed time:", time.Since(timeStart))
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Dense version of SeqResults. A sequence of four diffs in [-9, 9] is a base-19
+// number so it indexes straight into an array of NumSeqs totals. Each buyer
+// only counts the first time it sees a sequence, which is tracked with a
+// per-sequence stamp of the last buyer that hit it.
+
+type SeqIndex int32
+
+func (idx SeqIndex) AddAndShift(diff PriceDiff) SeqIndex {
+	return (idx*19 + SeqIndex(diff+9)) % NumSeqs
+}
+
+// Convert back to the packed Seq format for printing.
+func (idx SeqIndex) Seq() Seq {
+	var seq Seq
+	for i := 3; i >= 0; i-- {
+		seq |= Seq(idx%19) << (8 * (3 - i))
+		idx /= 19
+	}
+	return seq
+}
+
+type DenseSeqResults struct {
+	Totals [NumSeqs]int32
+	seen   [NumSeqs]int32
+}
+
+// Add the prices for one buyer. stamp must be unique per buyer and non-zero.
+func (dr *DenseSeqResults) Populate(stamp int32, secret uint32) {
+	var idx SeqIndex
+	prevPrice := Price(secret % 10)
+
+	for j := 0; j < NumSamples; j++ {
+		secret = Generate(secret)
+		price := Price(secret % 10)
+		idx = idx.AddAndShift(price.Diff(prevPrice))
+		prevPrice = price
+
+		if j >= 3 && dr.seen[idx] != stamp {
+			dr.seen[idx] = stamp
+			dr.Totals[idx] += int32(price)
+		}
+	}
+}
+
+func (dr *DenseSeqResults) Merge(other *DenseSeqResults) {
+	for i, total := range other.Totals {
+		dr.Totals[i] += total
+		if other.seen[i] != 0 {
+			dr.seen[i] = other.seen[i]
+		}
+	}
+}
+
+func (dr *DenseSeqResults) FindBestSeq() (Seq, int) {
+	best := SeqIndex(0)
+	for i, total := range dr.Totals {
+		if total > dr.Totals[best] {
+			best = SeqIndex(i)
+		}
+	}
+	return best.Seq(), int(dr.Totals[best])
+}
+
+func (dr *DenseSeqResults) NumSeen() int {
+	count := 0
+	for _, stamp := range dr.seen {
+		if stamp != 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Shard the buyers across goroutines, each with its own DenseSeqResults, and
+// merge the totals at the end.
+func PopulateDense(inputs []uint32) *DenseSeqResults {
+	numShards := min(runtime.NumCPU(), len(inputs))
+	shards := make([]*DenseSeqResults, numShards)
+
+	var wg sync.WaitGroup
+	for s := range shards {
+		shards[s] = &DenseSeqResults{}
+		wg.Add(1)
+		go func(dr *DenseSeqResults) {
+			defer wg.Done()
+			for i := s; i < len(inputs); i += numShards {
+				dr.Populate(int32(i+1), inputs[i])
+			}
+		}(shards[s])
+	}
+	wg.Wait()
+
+	if numShards == 0 {
+		return &DenseSeqResults{}
+	}
+	for _, dr := range shards[1:] {
+		shards[0].Merge(dr)
+	}
+	return shards[0]
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"time"
@@ -153,10 +154,43 @@ func (in *SeqResults) FindBestSeq() (Seq, int) {
 
 //-------------------------------------------------------------------------
 
+func PopulateMap(inputs []uint32) *SeqResults {
+	var results SeqResults
+	results.Init(len(inputs))
+
+	for inputIndex, secret := range inputs {
+		results.PopulateSeqResults(inputIndex, secret)
+	}
+	return &results
+}
+
+func RunMap(inputs []uint32) (Seq, int) {
+	results := PopulateMap(inputs)
+
+	fmt.Println("Unique sequences:", len(results.SeqPrices))
+
+	bestSeq, bestTotal := results.FindBestSeq()
+	fmt.Println("Best sequence:", bestSeq, "Total:", bestTotal)
+	return bestSeq, bestTotal
+}
+
+func RunDense(inputs []uint32) (Seq, int) {
+	results := PopulateDense(inputs)
+
+	fmt.Println("Unique sequences:", results.NumSeen())
+
+	bestSeq, bestTotal := results.FindBestSeq()
+	fmt.Println("Best sequence:", bestSeq, "Total:", bestTotal)
+	return bestSeq, bestTotal
+}
+
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	timeStart := time.Now()
 
+	impl := flag.String("impl", "dense", "implementation to use: map or dense")
+	flag.Parse()
+
 	Debug = false
 
 	lines := ReadFileLines("input.txt")
@@ -165,18 +199,14 @@ func main() {
 		inputs = append(inputs, uint32(MustAtoi(line)))
 	}
 
-	// Initialize SeqResults
-	var results SeqResults
-	results.Init(len(inputs))
-
-	for inputIndex, secret := range inputs {
-		results.PopulateSeqResults(inputIndex, secret)
+	switch *impl {
+	case "map":
+		RunMap(inputs)
+	case "dense":
+		RunDense(inputs)
+	default:
+		log.Fatalf("Unknown impl %q", *impl)
 	}
 
-	fmt.Println("Unique sequences:", len(results.SeqPrices))
-
-	bestSeq, bestTotal := results.FindBestSeq()
-	fmt.Println("Best sequence:", bestSeq, "Total:", bestTotal)
-
 	fmt.Println("Elapsed time:", time.Since(timeStart))
 }
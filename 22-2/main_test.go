@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func readInputs(tb testing.TB) []uint32 {
+	tb.Helper()
+	Debug = false
+	var inputs []uint32
+	for _, line := range ReadFileLines("input.txt") {
+		inputs = append(inputs, uint32(MustAtoi(line)))
+	}
+	return inputs
+}
+
+func TestFindBestSeq(t *testing.T) {
+	inputs := readInputs(t)
+	const wantSeq, wantTotal = Seq(0x070c060c), 1808
+
+	if seq, total := PopulateMap(inputs).FindBestSeq(); seq != wantSeq || total != wantTotal {
+		t.Errorf("map: got %v total %d, want %v total %d", seq, total, wantSeq, wantTotal)
+	}
+	if seq, total := PopulateDense(inputs).FindBestSeq(); seq != wantSeq || total != wantTotal {
+		t.Errorf("dense: got %v total %d, want %v total %d", seq, total, wantSeq, wantTotal)
+	}
+}
+
+func BenchmarkPopulateMap(b *testing.B) {
+	inputs := readInputs(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		PopulateMap(inputs)
+	}
+}
+
+func BenchmarkPopulateDense(b *testing.B) {
+	inputs := readInputs(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		PopulateDense(inputs)
+	}
+}
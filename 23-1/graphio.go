@@ -0,0 +1,523 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Reading and writing graphs as edge lists, Graphviz DOT and adjacency JSON.
+
+// Load a graph file, picking the format from the extension. Anything that
+// isn't .dot/.gv or .json is read as an edge list.
+func (g *Graph) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".dot", ".gv":
+		return g.LoadDOT(string(data))
+	case ".json":
+		return g.LoadAdjacencyJSON(data)
+	}
+	return g.LoadEdgeList(strings.Split(string(data), "\n"))
+}
+
+// ----------------------------------------------------------------------------
+// Edge lists
+//
+// One edge per line, either "from-to" (the puzzle format) or whitespace
+// separated "from to". Either form can be followed by a numeric weight. A line
+// with a single name and no dash registers an isolated node. Blank lines and
+// lines starting with '#' are skipped.
+
+func (g *Graph) LoadEdgeList(lines []string) error {
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		var from, to, weight string
+		isDash := strings.Contains(fields[0], "-") &&
+			(len(fields) == 1 || (len(fields) == 2 && isNumber(fields[1])))
+		if isDash {
+			from, to, _ = strings.Cut(fields[0], "-")
+			if len(fields) == 2 {
+				weight = fields[1]
+			}
+		} else {
+			switch len(fields) {
+			case 1:
+				g.GetNodeID(fields[0])
+				continue
+			case 2:
+				from, to = fields[0], fields[1]
+			case 3:
+				from, to, weight = fields[0], fields[1], fields[2]
+			default:
+				return fmt.Errorf("line %d: expected 'from to [weight]': %q", i+1, line)
+			}
+		}
+
+		if from == "" || to == "" || from == to {
+			return fmt.Errorf("line %d: invalid edge: %q", i+1, line)
+		}
+
+		fromID, toID := g.GetNodeID(from), g.GetNodeID(to)
+		if weight == "" {
+			g.AddEdge(fromID, toID)
+			continue
+		}
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid weight %q", i+1, weight)
+		}
+		g.AddWeightedEdge(fromID, toID, w)
+	}
+	return nil
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// Write the graph in the whitespace separated edge list format. Names that
+// wouldn't read back as themselves are an error: ones with whitespace, ones
+// with a dash (an unweighted "a-b 3" reads as a-b with weight 3) and ones
+// that would look like a comment.
+func (g *Graph) WriteEdgeList(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, name := range g.NodeNames {
+		if name == "" || name[0] == '#' || strings.Contains(name, "-") ||
+			strings.IndexFunc(name, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("node name %q can't be written as an edge list", name)
+		}
+	}
+
+	for id := NodeID(0); id < NodeID(g.GetNumNodes()); id++ {
+		if g.GetNodeDegree(id) == 0 {
+			fmt.Fprintln(bw, g.GetNodeName(id))
+		}
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(bw, "%s %s", g.GetNodeName(e.A), g.GetNodeName(e.B))
+		if weight, ok := g.Weights[e]; ok {
+			fmt.Fprintf(bw, " %s", strconv.FormatFloat(weight, 'g', -1, 64))
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+// ----------------------------------------------------------------------------
+// Graphviz DOT
+
+// A set of nodes to call out in DOT output. Nodes are filled with Color and
+// edges between two nodes of the same highlight are drawn in Color too.
+type Highlight struct {
+	Label string
+	Color string
+	Nodes []NodeID
+}
+
+var HighlightColors = []string{
+	"tomato", "gold", "lightskyblue", "palegreen", "plum", "orange", "turquoise", "pink",
+}
+
+// Build highlights for a list of found structures (cliques, components, ...)
+// cycling through HighlightColors.
+func NewHighlights(label string, groups ...[]NodeID) []Highlight {
+	ret := make([]Highlight, len(groups))
+	for i, group := range groups {
+		ret[i] = Highlight{
+			Label: fmt.Sprintf("%s %d", label, i),
+			Color: HighlightColors[i%len(HighlightColors)],
+			Nodes: group,
+		}
+	}
+	return ret
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+func (g *Graph) WriteDOT(w io.Writer, highlights ...Highlight) error {
+	bw := bufio.NewWriter(w)
+
+	// First highlight wins if a node shows up in more than one.
+	nodeColor := make(map[NodeID]string)
+	nodeGroup := make(map[NodeID]int)
+	for i, h := range highlights {
+		for _, id := range h.Nodes {
+			if _, ok := nodeColor[id]; !ok {
+				nodeColor[id] = h.Color
+				nodeGroup[id] = i
+			}
+		}
+	}
+
+	fmt.Fprintln(bw, "graph G {")
+	for _, h := range highlights {
+		fmt.Fprintf(bw, "  // %s (%s): %d nodes\n", h.Label, h.Color, len(h.Nodes))
+	}
+
+	for id := NodeID(0); id < NodeID(g.GetNumNodes()); id++ {
+		fmt.Fprintf(bw, "  %s", dotQuote(g.GetNodeName(id)))
+		if color, ok := nodeColor[id]; ok {
+			fmt.Fprintf(bw, " [style=filled, fillcolor=%s]", dotQuote(color))
+		}
+		fmt.Fprintln(bw, ";")
+	}
+
+	for _, e := range g.Edges() {
+		attrs := []string{}
+		if weight, ok := g.Weights[e]; ok {
+			attrs = append(attrs, "weight="+strconv.FormatFloat(weight, 'g', -1, 64))
+		}
+		ga, aok := nodeGroup[e.A]
+		gb, bok := nodeGroup[e.B]
+		if aok && bok && ga == gb {
+			attrs = append(attrs, "color="+dotQuote(nodeColor[e.A]), "penwidth=3")
+		}
+
+		fmt.Fprintf(bw, "  %s -- %s", dotQuote(g.GetNodeName(e.A)), dotQuote(g.GetNodeName(e.B)))
+		if len(attrs) > 0 {
+			fmt.Fprintf(bw, " [%s]", strings.Join(attrs, ", "))
+		}
+		fmt.Fprintln(bw, ";")
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+type dotToken struct {
+	Text   string
+	Quoted bool
+}
+
+func tokenizeDOT(src string) ([]dotToken, error) {
+	tokens := []dotToken{}
+	isIDChar := func(r byte) bool {
+		return r == '_' || r == '.' || r >= 0x80 ||
+			(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case strings.HasPrefix(src[i:], "//") || (c == '#' && (i == 0 || src[i-1] == '\n')):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case strings.HasPrefix(src[i:], "--") || strings.HasPrefix(src[i:], "->"):
+			tokens = append(tokens, dotToken{Text: src[i : i+2]})
+			i += 2
+		case strings.ContainsRune("{}[]=;,", rune(c)):
+			tokens = append(tokens, dotToken{Text: string(c)})
+			i++
+		case c == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' && j+1 < len(src) && (src[j+1] == '"' || src[j+1] == '\\') {
+					j++
+				}
+				sb.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, dotToken{Text: sb.String(), Quoted: true})
+			i = j + 1
+		case isIDChar(c) || c == '-':
+			j := i + 1
+			for j < len(src) && isIDChar(src[j]) {
+				j++
+			}
+			tokens = append(tokens, dotToken{Text: src[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q in DOT", c)
+		}
+	}
+	return tokens, nil
+}
+
+// Load a DOT graph. Only the flat subset is supported: node statements, edge
+// chains with optional attribute lists and graph/node/edge defaults. Direction
+// is ignored and a "weight" attribute becomes the edge weight.
+func (g *Graph) LoadDOT(src string) error {
+	tokens, err := tokenizeDOT(src)
+	if err != nil {
+		return err
+	}
+
+	pos := 0
+	peek := func() string {
+		if pos < len(tokens) && !tokens[pos].Quoted {
+			return tokens[pos].Text
+		}
+		return ""
+	}
+	next := func() (dotToken, error) {
+		if pos >= len(tokens) {
+			return dotToken{}, fmt.Errorf("unexpected end of DOT")
+		}
+		pos++
+		return tokens[pos-1], nil
+	}
+	expect := func(text string) error {
+		t, err := next()
+		if err != nil {
+			return err
+		}
+		if t.Quoted || t.Text != text {
+			return fmt.Errorf("expected %q in DOT, got %q", text, t.Text)
+		}
+		return nil
+	}
+	readID := func() (string, error) {
+		t, err := next()
+		if err != nil {
+			return "", err
+		}
+		if !t.Quoted {
+			switch t.Text {
+			case "{", "}", "[", "]", "=", ";", ",", "--", "->":
+				return "", fmt.Errorf("expected ID in DOT, got %q", t.Text)
+			}
+		}
+		return t.Text, nil
+	}
+	readAttrs := func() (map[string]string, error) {
+		attrs := make(map[string]string)
+		for peek() == "[" {
+			pos++
+			for peek() != "]" {
+				key, err := readID()
+				if err != nil {
+					return nil, err
+				}
+				if err := expect("="); err != nil {
+					return nil, err
+				}
+				val, err := readID()
+				if err != nil {
+					return nil, err
+				}
+				attrs[key] = val
+				if p := peek(); p == "," || p == ";" {
+					pos++
+				}
+			}
+			pos++
+		}
+		return attrs, nil
+	}
+
+	if peek() == "strict" {
+		pos++
+	}
+	if p := peek(); p != "graph" && p != "digraph" {
+		return fmt.Errorf("expected graph or digraph in DOT")
+	}
+	pos++
+	if peek() != "{" {
+		if _, err := readID(); err != nil {
+			return err
+		}
+	}
+	if err := expect("{"); err != nil {
+		return err
+	}
+
+	for peek() != "}" {
+		if peek() == ";" {
+			pos++
+			continue
+		}
+
+		switch peek() {
+		case "graph", "node", "edge":
+			pos++
+			if _, err := readAttrs(); err != nil {
+				return err
+			}
+			continue
+		case "subgraph", "{":
+			return fmt.Errorf("subgraphs are not supported in DOT")
+		}
+
+		first, err := readID()
+		if err != nil {
+			return err
+		}
+		if peek() == "=" {
+			// Graph attribute
+			pos++
+			if _, err := readID(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		chain := []string{first}
+		for p := peek(); p == "--" || p == "->"; p = peek() {
+			pos++
+			id, err := readID()
+			if err != nil {
+				return err
+			}
+			chain = append(chain, id)
+		}
+
+		attrs, err := readAttrs()
+		if err != nil {
+			return err
+		}
+
+		var weight float64
+		weightStr, hasWeight := attrs["weight"]
+		if hasWeight {
+			if weight, err = strconv.ParseFloat(weightStr, 64); err != nil {
+				return fmt.Errorf("invalid weight %q in DOT", weightStr)
+			}
+		}
+
+		if len(chain) == 1 {
+			g.GetNodeID(first)
+			continue
+		}
+		for i := 1; i < len(chain); i++ {
+			from, to := g.GetNodeID(chain[i-1]), g.GetNodeID(chain[i])
+			if from == to {
+				return fmt.Errorf("self loop on %q in DOT", chain[i])
+			}
+			if hasWeight {
+				g.AddWeightedEdge(from, to, weight)
+			} else {
+				g.AddEdge(from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Adjacency JSON
+//
+// An object mapping each node name to its neighbors. Unweighted graphs use a
+// list of names ({"a": ["b", "c"]}) and weighted graphs use an object of
+// weights ({"a": {"b": 1.5}}) where null means the edge has no weight.
+
+func (g *Graph) WriteAdjacencyJSON(w io.Writer) error {
+	var out any
+	if len(g.Weights) == 0 {
+		adj := make(map[string][]string, g.GetNumNodes())
+		for id, name := range g.NodeNames {
+			neighbors := []string{}
+			for _, n := range g.Neighbors(NodeID(id)) {
+				neighbors = append(neighbors, g.GetNodeName(n))
+			}
+			slices.Sort(neighbors)
+			adj[name] = neighbors
+		}
+		out = adj
+	} else {
+		adj := make(map[string]map[string]*float64, g.GetNumNodes())
+		for id, name := range g.NodeNames {
+			neighbors := make(map[string]*float64)
+			for _, n := range g.Neighbors(NodeID(id)) {
+				var weight *float64
+				if w, ok := g.EdgeWeight(NodeID(id), n); ok {
+					weight = &w
+				}
+				neighbors[g.GetNodeName(n)] = weight
+			}
+			adj[name] = neighbors
+		}
+		out = adj
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func (g *Graph) LoadAdjacencyJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	// Register nodes in name order so IDs don't depend on map order.
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		g.GetNodeID(name)
+	}
+
+	for _, name := range names {
+		from := g.GetNodeID(name)
+
+		var list []string
+		if err := json.Unmarshal(raw[name], &list); err == nil {
+			for _, n := range list {
+				to := g.GetNodeID(n)
+				if from == to {
+					return fmt.Errorf("self loop on %q", name)
+				}
+				g.AddEdge(from, to)
+			}
+			continue
+		}
+
+		var weighted map[string]*float64
+		if err := json.Unmarshal(raw[name], &weighted); err != nil {
+			return fmt.Errorf("neighbors of %q: %w", name, err)
+		}
+		neighbors := make([]string, 0, len(weighted))
+		for n := range weighted {
+			neighbors = append(neighbors, n)
+		}
+		slices.Sort(neighbors)
+		for _, n := range neighbors {
+			to, weight := g.GetNodeID(n), weighted[n]
+			if from == to {
+				return fmt.Errorf("self loop on %q", name)
+			}
+			if weight == nil {
+				g.AddEdge(from, to)
+			} else {
+				g.AddWeightedEdge(from, to, *weight)
+			}
+		}
+	}
+	return nil
+}
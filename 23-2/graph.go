@@ -0,0 +1,457 @@
+package main
+
+import (
+	"math/bits"
+	"slices"
+)
+
+// Undirected graph with named nodes. Nodes can be registered at any time and
+// the adjacency can be stored sparse (sets per node) or dense (bit matrix).
+
+type NodeID int
+
+// ----------------------------------------------------------------------------
+// Adjacency storage
+
+type Adjacency interface {
+	// Grow the storage to hold one more node.
+	AddNode()
+	AddEdge(a, b NodeID) bool
+	HasEdge(a, b NodeID) bool
+	// Neighbors in increasing ID order.
+	Neighbors(a NodeID) []NodeID
+	Degree(a NodeID) int
+}
+
+// Sparse adjacency: a sorted neighbor list per node.
+type SparseAdjacency struct {
+	adj [][]NodeID
+}
+
+func (sa *SparseAdjacency) AddNode() {
+	sa.adj = append(sa.adj, nil)
+}
+
+func (sa *SparseAdjacency) insert(a, b NodeID) bool {
+	i, found := slices.BinarySearch(sa.adj[a], b)
+	if found {
+		return false
+	}
+	sa.adj[a] = slices.Insert(sa.adj[a], i, b)
+	return true
+}
+
+func (sa *SparseAdjacency) AddEdge(a, b NodeID) bool {
+	if !sa.insert(a, b) {
+		return false
+	}
+	sa.insert(b, a)
+	return true
+}
+
+func (sa *SparseAdjacency) HasEdge(a, b NodeID) bool {
+	_, found := slices.BinarySearch(sa.adj[a], b)
+	return found
+}
+
+func (sa *SparseAdjacency) Neighbors(a NodeID) []NodeID {
+	return sa.adj[a]
+}
+
+func (sa *SparseAdjacency) Degree(a NodeID) int {
+	return len(sa.adj[a])
+}
+
+// Dense adjacency: one bitset row per node. Rows grow as nodes are added.
+type DenseAdjacency struct {
+	rows    [][]uint64
+	degrees []int
+}
+
+func (da *DenseAdjacency) AddNode() {
+	da.rows = append(da.rows, nil)
+	da.degrees = append(da.degrees, 0)
+}
+
+func (da *DenseAdjacency) set(a, b NodeID) {
+	word := int(b) / 64
+	for len(da.rows[a]) <= word {
+		da.rows[a] = append(da.rows[a], 0)
+	}
+	da.rows[a][word] |= 1 << (uint(b) % 64)
+}
+
+func (da *DenseAdjacency) AddEdge(a, b NodeID) bool {
+	if da.HasEdge(a, b) {
+		return false
+	}
+	da.set(a, b)
+	da.set(b, a)
+	da.degrees[a]++
+	da.degrees[b]++
+	return true
+}
+
+func (da *DenseAdjacency) HasEdge(a, b NodeID) bool {
+	row := da.rows[a]
+	word := int(b) / 64
+	return word < len(row) && row[word]&(1<<(uint(b)%64)) != 0
+}
+
+func (da *DenseAdjacency) Neighbors(a NodeID) []NodeID {
+	ret := make([]NodeID, 0, da.degrees[a])
+	for w, word := range da.rows[a] {
+		for word != 0 {
+			b := bits.TrailingZeros64(word)
+			ret = append(ret, NodeID(w*64+b))
+			word &= word - 1
+		}
+	}
+	return ret
+}
+
+func (da *DenseAdjacency) Degree(a NodeID) int {
+	return da.degrees[a]
+}
+
+// ----------------------------------------------------------------------------
+type Graph struct {
+	Adj       Adjacency
+	NodeNames []string
+	NodeIDs   map[string]NodeID
+	NumEdges  int
+
+	// Optional edge weights keyed by MakeEdge(from, to).
+	Weights map[Edge]float64
+}
+
+// An undirected edge with the smaller ID first.
+type Edge struct {
+	A, B NodeID
+}
+
+func MakeEdge(a, b NodeID) Edge {
+	if a > b {
+		a, b = b, a
+	}
+	return Edge{a, b}
+}
+
+func NewGraph() *Graph {
+	return NewGraphWithAdjacency(&SparseAdjacency{})
+}
+
+func NewDenseGraph() *Graph {
+	return NewGraphWithAdjacency(&DenseAdjacency{})
+}
+
+func NewGraphWithAdjacency(adj Adjacency) *Graph {
+	return &Graph{
+		Adj:     adj,
+		NodeIDs: make(map[string]NodeID),
+		Weights: make(map[Edge]float64),
+	}
+}
+
+// Get the ID for a node, registering it if it is new.
+func (g *Graph) GetNodeID(name string) NodeID {
+	if id, ok := g.NodeIDs[name]; ok {
+		return id
+	}
+	id := NodeID(len(g.NodeNames))
+	g.NodeNames = append(g.NodeNames, name)
+	g.NodeIDs[name] = id
+	g.Adj.AddNode()
+	return id
+}
+
+func (g *Graph) GetNodeName(id NodeID) string {
+	return g.NodeNames[id]
+}
+
+func (g *Graph) GetNumNodes() int {
+	return len(g.NodeNames)
+}
+
+func (g *Graph) GetNodeDegree(id NodeID) int {
+	return g.Adj.Degree(id)
+}
+
+func (g *Graph) HasEdge(from, to NodeID) bool {
+	return g.Adj.HasEdge(from, to)
+}
+
+func (g *Graph) Neighbors(id NodeID) []NodeID {
+	return g.Adj.Neighbors(id)
+}
+
+func (g *Graph) AddEdge(from, to NodeID) {
+	Assert(from != to, "Self loops not supported: "+g.GetNodeName(from))
+	if g.Adj.AddEdge(from, to) {
+		g.NumEdges++
+		DebugLogf("Edge: %s-%s\n", g.GetNodeName(from), g.GetNodeName(to))
+	}
+}
+
+func (g *Graph) AddNamedEdge(from, to string) {
+	g.AddEdge(g.GetNodeID(from), g.GetNodeID(to))
+}
+
+func (g *Graph) AddWeightedEdge(from, to NodeID, weight float64) {
+	g.AddEdge(from, to)
+	g.Weights[MakeEdge(from, to)] = weight
+}
+
+func (g *Graph) EdgeWeight(from, to NodeID) (float64, bool) {
+	w, ok := g.Weights[MakeEdge(from, to)]
+	return w, ok
+}
+
+// All edges in increasing order.
+func (g *Graph) Edges() []Edge {
+	ret := make([]Edge, 0, g.NumEdges)
+	for a := NodeID(0); a < NodeID(g.GetNumNodes()); a++ {
+		for _, b := range g.Neighbors(a) {
+			if b > a {
+				ret = append(ret, Edge{a, b})
+			}
+		}
+	}
+	return ret
+}
+
+// ----------------------------------------------------------------------------
+// Cliques
+
+type Clique []NodeID
+
+func NewClique(ids ...NodeID) Clique {
+	ret := make(Clique, len(ids))
+	copy(ret, ids)
+	return ret
+}
+
+func (c Clique) Clone() Clique {
+	clone := make(Clique, len(c))
+	copy(clone, c)
+	return clone
+}
+
+// Names of the nodes in the clique, sorted.
+func (c Clique) Names(g *Graph) []string {
+	names := make([]string, len(c))
+	for i, id := range c {
+		names[i] = g.GetNodeName(id)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Return the intersection of two sorted ID lists.
+func intersect(a, b []NodeID) []NodeID {
+	ret := []NodeID{}
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			ret = append(ret, a[i])
+			i++
+			j++
+		}
+	}
+	return ret
+}
+
+// Return a sorted list with the items in b removed from a.
+func subtract(a, b []NodeID) []NodeID {
+	ret := []NodeID{}
+	j := 0
+	for _, id := range a {
+		for j < len(b) && b[j] < id {
+			j++
+		}
+		if j < len(b) && b[j] == id {
+			continue
+		}
+		ret = append(ret, id)
+	}
+	return ret
+}
+
+// Find every triangle. Each one is reported once with IDs in increasing order.
+func (g *Graph) Triangles() []Clique {
+	ret := []Clique{}
+	for u := NodeID(0); u < NodeID(g.GetNumNodes()); u++ {
+		nu := g.Neighbors(u)
+		for _, v := range nu {
+			if v <= u {
+				continue
+			}
+			for _, w := range intersect(nu, g.Neighbors(v)) {
+				if w > v {
+					ret = append(ret, NewClique(u, v, w))
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// Order the nodes by repeatedly removing a node of minimum degree. Returns the
+// order and the degeneracy of the graph (the largest degree seen at removal).
+func (g *Graph) DegeneracyOrder() ([]NodeID, int) {
+	numNodes := g.GetNumNodes()
+	degree := make([]int, numNodes)
+	maxDegree := 0
+	for id := range degree {
+		degree[id] = g.GetNodeDegree(NodeID(id))
+		maxDegree = max(maxDegree, degree[id])
+	}
+
+	// Bucket queue keyed by current degree.
+	buckets := make([][]NodeID, maxDegree+1)
+	for id, d := range degree {
+		buckets[d] = append(buckets[d], NodeID(id))
+	}
+
+	removed := make([]bool, numNodes)
+	order := make([]NodeID, 0, numNodes)
+	degeneracy := 0
+	d := 0
+	for len(order) < numNodes {
+		// The minimum can drop by at most one per removal.
+		d = max(d-1, 0)
+		for len(buckets[d]) == 0 {
+			d++
+		}
+
+		id := buckets[d][len(buckets[d])-1]
+		buckets[d] = buckets[d][:len(buckets[d])-1]
+		if removed[id] || degree[id] != d {
+			// Stale entry
+			continue
+		}
+
+		removed[id] = true
+		order = append(order, id)
+		degeneracy = max(degeneracy, d)
+		for _, n := range g.Neighbors(id) {
+			if !removed[n] {
+				degree[n]--
+				buckets[degree[n]] = append(buckets[degree[n]], n)
+			}
+		}
+	}
+	return order, degeneracy
+}
+
+// Find all maximal cliques with Bron–Kerbosch. The outer level walks the
+// degeneracy order and the inner recursion pivots on the node with the most
+// neighbors in P so the search stays small on sparse graphs.
+func (g *Graph) MaximalCliques() []Clique {
+	ret := []Clique{}
+	g.bronKerbosch(func(c Clique) {
+		ret = append(ret, c.Clone())
+	})
+	return ret
+}
+
+// Find a maximum clique. Ties are broken by the first one found.
+func (g *Graph) MaxClique() Clique {
+	best := Clique{}
+	g.bronKerbosch(func(c Clique) {
+		if len(c) > len(best) {
+			best = c.Clone()
+		}
+	})
+	return best
+}
+
+func (g *Graph) bronKerbosch(report func(Clique)) {
+	order, _ := g.DegeneracyOrder()
+	position := make([]int, len(order))
+	for i, id := range order {
+		position[id] = i
+	}
+
+	r := Clique{}
+	for _, v := range order {
+		p := []NodeID{}
+		x := []NodeID{}
+		for _, n := range g.Neighbors(v) {
+			if position[n] > position[v] {
+				p = append(p, n)
+			} else {
+				x = append(x, n)
+			}
+		}
+		g.bronKerboschPivot(append(r, v), p, x, report)
+	}
+}
+
+func (g *Graph) bronKerboschPivot(r Clique, p, x []NodeID, report func(Clique)) {
+	if len(p) == 0 {
+		if len(x) == 0 {
+			report(r)
+		}
+		return
+	}
+
+	// Choose the pivot from P ∪ X that covers the most of P.
+	pivot := NodeID(-1)
+	best := -1
+	for _, set := range [][]NodeID{p, x} {
+		for _, u := range set {
+			n := len(intersect(p, g.Neighbors(u)))
+			if n > best {
+				best = n
+				pivot = u
+			}
+		}
+	}
+
+	for _, v := range subtract(p, g.Neighbors(pivot)) {
+		nv := g.Neighbors(v)
+		g.bronKerboschPivot(append(r, v), intersect(p, nv), intersect(x, nv), report)
+
+		p = subtract(p, []NodeID{v})
+		i, _ := slices.BinarySearch(x, v)
+		x = slices.Insert(slices.Clone(x), i, v)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Connected components, each sorted by ID. Components are ordered by their
+// smallest node.
+func (g *Graph) ConnectedComponents() [][]NodeID {
+	numNodes := g.GetNumNodes()
+	seen := make([]bool, numNodes)
+	ret := [][]NodeID{}
+
+	for start := NodeID(0); start < NodeID(numNodes); start++ {
+		if seen[start] {
+			continue
+		}
+		seen[start] = true
+		component := []NodeID{}
+		stack := []NodeID{start}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			component = append(component, id)
+			for _, n := range g.Neighbors(id) {
+				if !seen[n] {
+					seen[n] = true
+					stack = append(stack, n)
+				}
+			}
+		}
+		slices.Sort(component)
+		ret = append(ret, component)
+	}
+	return ret
+}
@@ -3,7 +3,10 @@ package main
 import (
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"regexp"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -39,6 +42,7 @@ type LogicNode struct {
 	Type     NodeType
 	Val      bool
 	Computed bool
+	Lanes    uint64
 	Inputs   [2]*LogicNode
 	Outputs  []*LogicNode
 }
@@ -60,39 +64,80 @@ func (ln *LogicNode) ValString() string {
 	return "?"
 }
 
+// Compute the value of this node from its inputs. The inputs must already be
+// computed -- LogicGraph.Compute takes care of visiting nodes in topological
+// order so this never has to recurse.
 func (ln *LogicNode) Compute() bool {
-	if ln.Computed {
+	switch ln.Type {
+	case Constant:
+		Assert(ln.Computed, "Constant node should have been computed already")
 		return ln.Val
+	case Unknown:
+		Assert(false, "Unknown node type for %s", ln.Name)
+		return false
 	}
 
 	for _, in := range ln.Inputs {
-		if in != nil && !in.Computed {
-			in.Compute()
-		}
+		Assert(in.Computed, "Input %s of %s not computed", in.Name, ln.Name)
 	}
 
 	switch ln.Type {
-	case Constant:
-		Assert(false, "Constant node should have been computed already")
 	case AND:
 		ln.Val = ln.Inputs[0].Val && ln.Inputs[1].Val
 	case OR:
 		ln.Val = ln.Inputs[0].Val || ln.Inputs[1].Val
 	case XOR:
 		ln.Val = ln.Inputs[0].Val != ln.Inputs[1].Val
-	case Unknown:
-		Assert(false, "Unknown node type")
 	}
 	ln.Computed = true
 	return ln.Val
 }
 
+// Compute 64 independent evaluations of this node at once. Bit i of Lanes is
+// the value of the node for test vector i.
+func (ln *LogicNode) ComputeLanes() uint64 {
+	switch ln.Type {
+	case AND:
+		ln.Lanes = ln.Inputs[0].Lanes & ln.Inputs[1].Lanes
+	case OR:
+		ln.Lanes = ln.Inputs[0].Lanes | ln.Inputs[1].Lanes
+	case XOR:
+		ln.Lanes = ln.Inputs[0].Lanes ^ ln.Inputs[1].Lanes
+	}
+	return ln.Lanes
+}
+
+// ----------------------------------------
+// CycleError is returned when the rules (usually after a bad swap) make the
+// graph cyclic so it can't be evaluated. Nodes is one loop, each node feeding
+// the next and the last feeding the first.
+type CycleError struct {
+	Nodes []string
+}
+
+func (ce *CycleError) Error() string {
+	return fmt.Sprintf("logic graph has a cycle through %d nodes: %s", len(ce.Nodes), strings.Join(ce.Nodes, " -> "))
+}
+
+// UndefinedError is returned when rules read wires that no rule or constant
+// defines.
+type UndefinedError struct {
+	Wires []string
+}
+
+func (ue *UndefinedError) Error() string {
+	return fmt.Sprintf("logic graph reads %d undefined wires: %s", len(ue.Wires), strings.Join(ue.Wires, ", "))
+}
+
 // ----------------------------------------
 type LogicGraph struct {
 	Nodes   map[string]*LogicNode
 	Aliases map[string]*LogicNode
 	Outputs []*LogicNode
 	Swaps   map[string]string
+
+	// Cached topological order. Cleared whenever the graph changes.
+	order []*LogicNode
 }
 
 func NewLogicGraph() *LogicGraph {
@@ -111,6 +156,7 @@ func (lg *LogicGraph) GetNode(name string) *LogicNode {
 			Type: Unknown,
 		}
 		lg.Nodes[name] = node
+		lg.order = nil
 
 		if name[0] == 'z' {
 			lg.Outputs = append(lg.Outputs, node)
@@ -146,6 +192,7 @@ func (lg *LogicGraph) AddRule(in1, in2 string, op NodeType, out string) {
 	}
 
 	node := lg.GetNode(out)
+	lg.order = nil
 	node.Type = op
 	node.Inputs[0] = lg.GetNode(in1)
 	node.Inputs[1] = lg.GetNode(in2)
@@ -153,10 +200,113 @@ func (lg *LogicGraph) AddRule(in1, in2 string, op NodeType, out string) {
 	node.Inputs[1].Outputs = append(node.Inputs[1].Outputs, node)
 }
 
-func (lg *LogicGraph) Compute() {
-	for _, node := range lg.Outputs {
-		node.Compute()
+// Return the nodes in an order where every node comes after its inputs. This
+// uses Kahn's algorithm so a cycle is reported as an error instead of blowing
+// the stack.
+func (lg *LogicGraph) TopoOrder() ([]*LogicNode, error) {
+	if lg.order != nil {
+		return lg.order, nil
+	}
+
+	undefined := []string{}
+	for _, node := range lg.Nodes {
+		if node.Type == Unknown {
+			undefined = append(undefined, node.Name)
+		}
+	}
+	if len(undefined) > 0 {
+		slices.Sort(undefined)
+		return nil, &UndefinedError{Wires: undefined}
+	}
+
+	inDegree := make(map[*LogicNode]int, len(lg.Nodes))
+	queue := []*LogicNode{}
+	for _, node := range lg.Nodes {
+		for _, in := range node.Inputs {
+			if in != nil {
+				inDegree[node]++
+			}
+		}
+		if inDegree[node] == 0 {
+			queue = append(queue, node)
+		}
+	}
+
+	// Sort the starting set so the order is deterministic.
+	slices.SortFunc(queue, func(a, b *LogicNode) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	order := make([]*LogicNode, 0, len(lg.Nodes))
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		order = append(order, node)
+
+		for _, out := range node.Outputs {
+			inDegree[out]--
+			if inDegree[out] == 0 {
+				queue = append(queue, out)
+			}
+		}
+	}
+
+	if len(order) != len(lg.Nodes) {
+		return nil, &CycleError{Nodes: findCycle(inDegree)}
+	}
+
+	lg.order = order
+	return order, nil
+}
+
+// Every node left over when Kahn's algorithm stops has an input that is also
+// left over. Some are only downstream of a cycle, so follow inputs back until
+// a node repeats and return just that loop, in signal order.
+func findCycle(inDegree map[*LogicNode]int) []string {
+	var node *LogicNode
+	for n, d := range inDegree {
+		if d > 0 && (node == nil || n.Name < node.Name) {
+			node = n
+		}
 	}
+
+	seen := make(map[*LogicNode]int)
+	path := []string{}
+	for {
+		if i, ok := seen[node]; ok {
+			cycle := path[i:]
+			slices.Reverse(cycle)
+			return cycle
+		}
+		seen[node] = len(path)
+		path = append(path, node.Name)
+
+		// Take the stuck input with the lowest name so the result is stable.
+		var next *LogicNode
+		for _, in := range node.Inputs {
+			if in != nil && inDegree[in] > 0 && (next == nil || in.Name < next.Name) {
+				next = in
+			}
+		}
+		node = next
+	}
+}
+
+// Evaluate every node in topological order. All non-constant nodes are
+// recomputed so there is no need to Reset between different inputs.
+func (lg *LogicGraph) Compute() error {
+	order, err := lg.TopoOrder()
+	if err != nil {
+		return err
+	}
+
+	lg.Reset()
+	for _, node := range order {
+		if node.Type != Constant {
+			node.Compute()
+		}
+	}
+	return nil
 }
 
 func (lg *LogicGraph) Reset() {
@@ -167,6 +317,97 @@ func (lg *LogicGraph) Reset() {
 	}
 }
 
+// Return the nodes for an input bus ('x' or 'y') ordered by bit position.
+func (lg *LogicGraph) InputBus(prefix byte) []*LogicNode {
+	bus := []*LogicNode{}
+	for bit := 0; ; bit++ {
+		node, ok := lg.Nodes[fmt.Sprintf("%c%02d", prefix, bit)]
+		if !ok {
+			return bus
+		}
+		bus = append(bus, node)
+	}
+}
+
+// Set the x and y input constants from integers.
+func (lg *LogicGraph) SetInputs(x, y uint64) {
+	for bit, node := range lg.InputBus('x') {
+		node.Val = x&(1<<bit) != 0
+		node.Computed = true
+	}
+	for bit, node := range lg.InputBus('y') {
+		node.Val = y&(1<<bit) != 0
+		node.Computed = true
+	}
+}
+
+// Run up to 64 test vectors through the graph at once. Every wire carries a
+// uint64 where bit i is its value for (xs[i], ys[i]). The z output for each
+// vector is returned.
+func (lg *LogicGraph) Simulate(xs, ys []uint64) ([]uint64, error) {
+	Assert(len(xs) == len(ys), "Mismatched test vector lengths")
+	Assert(len(xs) <= 64, "At most 64 test vectors can be simulated at once")
+
+	order, err := lg.TopoOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	// Transpose the test vectors into per-wire lanes.
+	for bit, node := range lg.InputBus('x') {
+		node.Lanes = 0
+		for i, x := range xs {
+			node.Lanes |= (x >> bit & 1) << i
+		}
+	}
+	for bit, node := range lg.InputBus('y') {
+		node.Lanes = 0
+		for i, y := range ys {
+			node.Lanes |= (y >> bit & 1) << i
+		}
+	}
+
+	for _, node := range order {
+		if node.Type != Constant {
+			node.ComputeLanes()
+		}
+	}
+
+	zs := make([]uint64, len(xs))
+	for _, node := range lg.Outputs {
+		bitPos := MustAtoi(node.Name[1:])
+		for i := range zs {
+			zs[i] |= (node.Lanes >> i & 1) << bitPos
+		}
+	}
+	return zs, nil
+}
+
+// Throw random inputs at the graph and check that it adds. Returns a mask of
+// the output bits that were ever wrong.
+func (lg *LogicGraph) FuzzAdder(rounds int, rng *rand.Rand) (uint64, error) {
+	width := len(lg.InputBus('x'))
+	mask := uint64(1)<<width - 1
+
+	var bad uint64
+	xs := make([]uint64, 64)
+	ys := make([]uint64, 64)
+	for r := 0; r < rounds; r++ {
+		for i := range xs {
+			xs[i] = rng.Uint64() & mask
+			ys[i] = rng.Uint64() & mask
+		}
+		zs, err := lg.Simulate(xs, ys)
+		if err != nil {
+			return 0, err
+		}
+		for i, z := range zs {
+			bad |= z ^ (xs[i] + ys[i])
+		}
+	}
+	return bad, nil
+}
+
 func (lg *LogicGraph) Load(lines []string) {
 	var i int
 
@@ -210,8 +451,10 @@ func (lg *LogicGraph) Load(lines []string) {
 	}
 }
 
-func (lg *LogicGraph) GetOutput() int {
-	lg.Compute()
+func (lg *LogicGraph) GetOutput() (int, error) {
+	if err := lg.Compute(); err != nil {
+		return 0, err
+	}
 	result := 0
 	for _, node := range lg.Outputs {
 		bitPos := MustAtoi(node.Name[1:])
@@ -219,7 +462,7 @@ func (lg *LogicGraph) GetOutput() int {
 			result |= 1 << bitPos
 		}
 	}
-	return result
+	return result, nil
 }
 
 func (lg *LogicGraph) SetAlias(name, alias string) {
@@ -308,7 +551,21 @@ func main() {
 	lg.AddSwap("mmf", "vdk")
 
 	lg.Load(lines)
-	fmt.Println("Output:", lg.GetOutput())
+	output, err := lg.GetOutput()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Output:", output)
+
+	bad, err := lg.FuzzAdder(100, rand.New(rand.NewPCG(1, 2)))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if bad == 0 {
+		fmt.Println("Adder check: OK")
+	} else {
+		fmt.Printf("Adder check: bad output bits %b\n", bad)
+	}
 
 	fmt.Printf("Digit 0\n")
 	lg.PrintLogic("z00", 1)
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestUndefinedWire(t *testing.T) {
+	lg := NewLogicGraph()
+	lg.Load([]string{
+		"x00: 1",
+		"",
+		"x00 AND qqq -> z00",
+	})
+
+	_, err := lg.GetOutput()
+	var ue *UndefinedError
+	if !errors.As(err, &ue) {
+		t.Fatalf("got %v, want an *UndefinedError", err)
+	}
+	if want := []string{"qqq"}; !slices.Equal(ue.Wires, want) {
+		t.Errorf("got undefined wires %v, want %v", ue.Wires, want)
+	}
+}
+
+// aaa and bbb feed each other. z00 and ccc are only downstream of the loop
+// and mustn't be reported as part of it.
+func TestCycle(t *testing.T) {
+	lg := NewLogicGraph()
+	lg.Load([]string{
+		"x00: 1",
+		"y00: 0",
+		"",
+		"x00 AND bbb -> aaa",
+		"aaa OR y00 -> bbb",
+		"bbb XOR x00 -> ccc",
+		"ccc AND aaa -> z00",
+	})
+
+	_, err := lg.GetOutput()
+	var ce *CycleError
+	if !errors.As(err, &ce) {
+		t.Fatalf("got %v, want a *CycleError", err)
+	}
+	if want := []string{"bbb", "aaa"}; !slices.Equal(ce.Nodes, want) {
+		t.Errorf("got cycle %v, want %v", ce.Nodes, want)
+	}
+}